@@ -1,31 +1,140 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"math/rand"
+	"os"
 	"time"
 
+	"nightmare/internal/ai"
+	"nightmare/internal/biometric"
 	"nightmare/internal/core"
+	"nightmare/internal/world"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// difficulties - названия уровней сложности для флага -difficulty
+var difficulties = map[string]ai.Difficulty{
+	"easy":      ai.DifficultyEasy,
+	"normal":    ai.DifficultyNormal,
+	"hard":      ai.DifficultyHard,
+	"nightmare": ai.DifficultyNightmare,
+}
+
 func main() {
+	profilePath := flag.String("profile", ai.DefaultProfilePath(), "файл профиля игрока (пустая строка - не сохранять)")
+	resetProfile := flag.Bool("reset-profile", false, "удалить профиль игрока и начать с чистого листа")
+	decisionLog := flag.String("decision-log", ai.DefaultDecisionLogPath(), "файл журнала решений директора (пустая строка - не писать)")
+	exportProfile := flag.String("export-profile", "", "выгрузить профиль игрока в файл ('-' - в стандартный вывод) и выйти")
+	personality := flag.String("director", core.RandomPersonality, "личность директора: balanced, slow-burn, trickster, predator, chaos или random - новая в каждой игре")
+	difficulty := flag.String("difficulty", "normal", "сложность: easy, normal, hard, nightmare")
+	endless := flag.Bool("endless", false, "бесконечный кошмарный лес вместо мира 256x256")
+	forestDir := flag.String("forest", world.DefaultForestDir(), "каталог бесконечного леса: зерно и измененные чанки (пустая строка - не сохранять)")
+	heartRate := flag.String("heart-rate", "", "источник пульса: sim, file:ПУТЬ, udp:ПОРТ или ws://localhost:ПОРТ/ПУТЬ (пустая строка - без датчика)")
+	flag.Parse()
+
 	// Инициализация генератора случайных чисел
 	rand.Seed(time.Now().UnixNano())
 
+	if *exportProfile != "" {
+		if err := export(*profilePath, *exportProfile); err != nil {
+			log.Fatalf("Не удалось выгрузить профиль: %v", err)
+		}
+		return
+	}
+
+	if *resetProfile {
+		if err := ai.ResetPlayerProfile(*profilePath); err != nil {
+			log.Fatalf("Не удалось сбросить профиль: %v", err)
+		}
+	}
+
 	// Создание игры
-	game, err := core.NewGame()
+	game, err := core.NewGame(*profilePath)
 	if err != nil {
 		log.Fatalf("Не удалось создать игру: %v", err)
 	}
 
+	// Бесконечный лес растет из сохраненного зерна и помнит, что в нем изменилось
+	if *endless {
+		if err := game.SetEndlessForest(*forestDir); err != nil {
+			log.Fatalf("Не удалось открыть лес: %v", err)
+		}
+	}
+
+	// Личность директора: выбранная или новая случайная в каждой игре
+	if !game.SetPersonality(*personality) {
+		log.Fatalf("Неизвестная личность директора: %s", *personality)
+	}
+
+	// Сложность задает темп, с которым директор нагнетает и отпускает
+	level, ok := difficulties[*difficulty]
+	if !ok {
+		log.Fatalf("Неизвестная сложность: %s", *difficulty)
+	}
+	game.SetDifficulty(level)
+
+	// Журнал и датчик открываются только после проверки флагов: log.Fatalf не вызывает отложенные Close
+	if err := run(game, *decisionLog, *heartRate); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+// run открывает журнал решений и датчик пульса, запускает игровой цикл и сохраняет
+// то, что игра узнала об игроке. Журнал и датчик закрываются при любом исходе.
+func run(game *core.Game, decisionLog, heartRate string) error {
+	// Пульс игрока от моста датчика на этой же машине
+	if heartRate != "" {
+		source, err := biometric.Open(heartRate)
+		if err != nil {
+			return fmt.Errorf("не удалось подключить датчик пульса: %w", err)
+		}
+		defer source.Close()
+		game.SetBiometricSource(source)
+	}
+
+	// Журнал решений директора для последующего разбора
+	if decisionLog != "" {
+		if err := game.SetDecisionLog(decisionLog); err != nil {
+			log.Printf("Не удалось открыть журнал решений: %v", err)
+		}
+		defer game.CloseDecisionLog()
+	}
+
 	// Настройка окна
 	ebiten.SetWindowSize(800, 600)
 	ebiten.SetWindowTitle("Nightmare Forest")
 
 	// Запуск игрового цикла
 	if err := ebiten.RunGame(game); err != nil {
-		log.Fatalf("Игра завершилась с ошибкой: %v", err)
+		return fmt.Errorf("игра завершилась с ошибкой: %w", err)
 	}
+
+	// Сохраняем то, что игра узнала об игроке, и то, что он изменил в лесу
+	game.SaveProfile()
+	game.SaveForest()
+	return nil
+}
+
+// export выгружает сохраненный профиль игрока
+func export(profilePath, target string) error {
+	profile, err := ai.LoadPlayerProfile(profilePath)
+	if err != nil {
+		return err
+	}
+
+	if target == "-" {
+		return profile.Export(os.Stdout)
+	}
+
+	file, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return profile.Export(file)
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"nightmare/internal/ai"
+	"nightmare/internal/simulation"
+)
+
+// difficulties - названия уровней сложности для флага -difficulty
+var difficulties = map[string]ai.Difficulty{
+	"easy":      ai.DifficultyEasy,
+	"normal":    ai.DifficultyNormal,
+	"hard":      ai.DifficultyHard,
+	"nightmare": ai.DifficultyNightmare,
+}
+
+func main() {
+	config := simulation.DefaultConfig()
+	flag.IntVar(&config.Ticks, "ticks", config.Ticks, "сколько тиков играет каждый бот (60 тиков - секунда)")
+	flag.IntVar(&config.WorldSize, "world", config.WorldSize, "размер мира")
+	flag.Int64Var(&config.Seed, "seed", config.Seed, "зерно мира, директора и решений ботов")
+	persona := flag.String("persona", "", "играет только этот бот (cautious, bold, panic, methodical, reckless, hesitant)")
+	difficulty := flag.String("difficulty", "normal", "сложность: easy, normal, hard, nightmare")
+	flag.StringVar(&config.Director, "director", config.Director, "личность директора: balanced, slow-burn, trickster, predator, chaos или random")
+	flag.BoolVar(&config.HeartRate, "heart-rate", config.HeartRate, "подавать директору и наблюдателю смоделированный пульс ботов")
+	flag.Parse()
+
+	var ok bool
+	if config.Difficulty, ok = difficulties[*difficulty]; !ok {
+		log.Fatalf("Неизвестная сложность: %s", *difficulty)
+	}
+
+	// Без указания бота играют все
+	var reports []*simulation.Report
+	if *persona == "" {
+		var err error
+		if reports, err = simulation.RunAll(config); err != nil {
+			log.Fatalf("Симуляция не удалась: %v", err)
+		}
+	} else {
+		p, ok := simulation.FindPersona(*persona)
+		if !ok {
+			log.Fatalf("Неизвестный бот: %s", *persona)
+		}
+		report, err := simulation.Run(p, config)
+		if err != nil {
+			log.Fatalf("Симуляция не удалась: %v", err)
+		}
+		reports = append(reports, report)
+	}
+
+	correct, unfair := 0, 0
+	for _, report := range reports {
+		report.Write(os.Stdout)
+		if report.Correct() {
+			correct++
+		}
+		if !report.Fair() {
+			unfair++
+		}
+	}
+	log.Printf("Наблюдатель распознал %d из %d ботов", correct, len(reports))
+
+	// Нарушенная гарантия честности - ошибка, на которую должна реагировать сборка
+	if unfair > 0 {
+		log.Fatalf("Правила честности нарушены в %d из %d прогонов", unfair, len(reports))
+	}
+}
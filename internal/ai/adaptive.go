@@ -0,0 +1,183 @@
+package ai
+
+import (
+	"math"
+	"time"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+	"nightmare/internal/event"
+)
+
+const (
+	patternCount      = 3    // How many of the player's top patterns shape a scare
+	aheadDistance     = 15.0 // How far ahead of a fast-moving player scares are placed
+	patternIntensity  = 0.2  // How much a fear pattern raises or lowers intensity
+	recommendedWeight = 0.6  // Chance scale of following the observer's recommended scare type
+)
+
+// SetObserver sets the observer whose recommendations guide the director
+func (d *Director) SetObserver(observer *ObserverSystem) {
+	d.observer = observer
+}
+
+// SetEventManager sets the event manager that scare events are reported to
+func (d *Director) SetEventManager(events *event.EventManager) {
+	d.events = events
+}
+
+// recommendation returns the observer's current scare recommendation, or nil
+func (d *Director) recommendation() *ScareRecommendation {
+	if d.observer == nil {
+		return nil
+	}
+	return d.observer.GetScareRecommendation()
+}
+
+// topPatterns returns the most prominent behavior patterns of the player
+func (d *Director) topPatterns() []PlayerPattern {
+	if d.analyzer == nil {
+		return nil
+	}
+	return d.analyzer.GetTopPatterns(patternCount)
+}
+
+// updateBehaviorFromObservations replaces rough estimates with what the analyzer and observer found
+func (d *Director) updateBehaviorFromObservations() {
+	for _, pattern := range d.topPatterns() {
+		weight := clamp01(pattern.Weight)
+		switch pattern.Name {
+		case "explorer":
+			d.playerBehavior.ExplorationPreference = d.playerBehavior.ExplorationPreference*0.8 + weight*0.2
+		case "indecisive", "circler":
+			d.playerBehavior.ExplorationPreference = d.playerBehavior.ExplorationPreference*0.8 + (1-weight)*0.2
+		case "wall_hugger", "backtracker":
+			d.playerBehavior.RiskTolerance = d.playerBehavior.RiskTolerance*0.8 + (1-weight)*0.2
+		case "sprinter":
+			d.playerBehavior.MovementPreference = d.playerBehavior.MovementPreference*0.8 + weight*0.2
+		case "freezes_when_scared":
+			d.playerBehavior.ReactivityToScares = d.playerBehavior.ReactivityToScares*0.8 + weight*0.2
+		}
+	}
+
+	if d.observer == nil {
+		return
+	}
+
+	reactor := d.observer.GetPlayerReactorProfile()
+	risk := (reactor[ReactorBold] + reactor[ReactorReckless] - reactor[ReactorCautious] - reactor[ReactorHesitant] + 2) / 4
+	d.playerBehavior.RiskTolerance = d.playerBehavior.RiskTolerance*0.8 + clamp01(risk)*0.2
+}
+
+// scareTimingReady checks that the recommended wait before the next scare has passed
+func (d *Director) scareTimingReady(timeSinceLast time.Duration) bool {
+	recommendation := d.recommendation()
+	if recommendation == nil || d.phase == PhasePeak {
+		return true
+	}
+	return timeSinceLast >= recommendation.Timing
+}
+
+// recommendedChance scales the scare chance by the priority of the recommendation
+func (d *Director) recommendedChance(chance float64) float64 {
+	recommendation := d.recommendation()
+	if recommendation == nil {
+		return chance
+	}
+	return chance * (0.5 + recommendation.Priority)
+}
+
+// recommendedEventType follows the observer's recommended scare type with a chance based on its priority
+func (d *Director) recommendedEventType(fallback common.ScareEventType) common.ScareEventType {
+	recommendation := d.recommendation()
+	if recommendation == nil || common.Rand().Float64() >= recommendation.Priority*recommendedWeight {
+		return fallback
+	}
+
+	// The personality may rule the recommended type out
+	if d.personality.eventWeight(recommendation.ScareType) <= 0 {
+		return fallback
+	}
+	return recommendation.ScareType
+}
+
+// scareAnchor returns the point a scare is placed around, based on the recommendation and the player's patterns
+func (d *Director) scareAnchor() entity.Vector2D {
+	anchor := d.player.Position
+	if recommendation := d.recommendation(); recommendation != nil {
+		anchor = recommendation.Position
+	}
+
+	for _, pattern := range d.topPatterns() {
+		switch pattern.Name {
+		case "explorer", "determined":
+			// Fast movers are met where they are heading
+			return entity.Vector2D{
+				X: anchor.X + math.Cos(d.player.Direction)*aheadDistance,
+				Y: anchor.Y + math.Sin(d.player.Direction)*aheadDistance,
+			}
+
+		case "indecisive", "cautious":
+			// Players who keep returning are ambushed in their favorite places
+			if area, ok := d.nearestPreferredArea(); ok {
+				return area
+			}
+		}
+	}
+
+	return anchor
+}
+
+// nearestPreferredArea returns the player's most visited area closest to the player
+func (d *Director) nearestPreferredArea() (entity.Vector2D, bool) {
+	if d.analyzer == nil {
+		return entity.Vector2D{}, false
+	}
+
+	areas := d.analyzer.GetMovementAnalysis().PreferredAreas
+	if len(areas) == 0 {
+		return entity.Vector2D{}, false
+	}
+
+	best := areas[0]
+	for _, area := range areas[1:] {
+		if distance(area, d.player.Position) < distance(best, d.player.Position) {
+			best = area
+		}
+	}
+	return best, true
+}
+
+// patternIntensityScale adjusts scare intensity for how the player handles fear
+func (d *Director) patternIntensityScale() float64 {
+	scale := 1.0
+	for _, pattern := range d.topPatterns() {
+		switch pattern.Name {
+		case "fearless":
+			scale += patternIntensity
+		case "easily_scared":
+			scale -= patternIntensity
+		}
+	}
+
+	if recommendation := d.recommendation(); recommendation != nil {
+		scale *= 0.5 + recommendation.Intensity*0.5
+	}
+
+	return scale
+}
+
+// reportScare tells the observer that a scare has been triggered
+func (d *Director) reportScare(scare common.ScareEvent) {
+	if d.events == nil {
+		return
+	}
+	d.events.TriggerWithData(event.NewScareEvent(scare.Type, d, scare.Position, scare.Intensity))
+}
+
+// reportScareOutcome feeds a measured scare back to the analyzer
+func (d *Director) reportScareOutcome(scare common.ScareEvent, score float64) {
+	if d.analyzer != nil {
+		d.analyzer.RecordScareResponse(scare, score)
+	}
+}
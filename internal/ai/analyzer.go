@@ -10,6 +10,27 @@ import (
 	"nightmare/internal/entity"
 )
 
+const (
+	heatmapSize      = 50  // Heatmap cells along each side of the world
+	defaultWorldSize = 256 // World size assumed until SetWorldSize is called
+
+	signalPatternWindow = 2 * time.Minute // Micro-behaviors older than this no longer shape patterns
+)
+
+// signalPatterns names the pattern each repeated micro-behavior reveals,
+// and how many times it must be seen within the window
+var signalPatterns = map[SignalType]struct {
+	name, description string
+	threshold         int
+}{
+	SignalFreeze:     {"freezes_when_scared", "Player stops dead when something frightens them", 2},
+	SignalBacktrack:  {"backtracker", "Player often turns back the way they came", 3},
+	SignalCircling:   {"circler", "Player walks in circles, as if lost", 2},
+	SignalLookAround: {"looks_around", "Player keeps turning to look around", 3},
+	SignalWallHug:    {"wall_hugger", "Player keeps close to walls and tree lines", 2},
+	SignalSprint:     {"sprinter", "Player moves in sudden bursts", 3},
+}
+
 // PlayerPattern represents a player behavior pattern
 type PlayerPattern struct {
 	Name        string
@@ -48,15 +69,20 @@ type Analyzer struct {
 	areaVisits      map[string]int  // key: "x,y" for sector, value: number of visits
 	sectorsExplored map[string]bool // key: "x,y" for sector, value: whether explored
 
-	sectorSize float64     // size of one sector for analysis
-	heatmap    [][]float64 // visit heatmap
+	sectorSize float64         // size of one sector for analysis
+	heatmap    [][]float64     // visit heatmap: heatmapSize x heatmapSize cells covering the whole world, indexed [y][x]
+	heatOrigin entity.Vector2D // world position of the heatmap's corner
+	cellWidth  float64         // width of one heatmap cell in world units
+	cellHeight float64         // height of one heatmap cell in world units
+	maxHeat    float64         // visits of the most visited cell
 
 	scareResponses map[common.ScareEventType][]float64 // Changed to use common.ScareEventType
+	signals        []BehaviorSignal                    // Micro-behaviors recognized in raw movement
 }
 
 // NewAnalyzer creates a new analyzer
 func NewAnalyzer(player *entity.Player) *Analyzer {
-	return &Analyzer{
+	analyzer := &Analyzer{
 		player:           player,
 		detectedPatterns: []PlayerPattern{},
 		movementAnalysis: MovementAnalysis{
@@ -74,14 +100,33 @@ func NewAnalyzer(player *entity.Player) *Analyzer {
 			SanityLossRate:        0,
 		},
 		scareHistory:     []common.ScareEvent{}, // Changed to use common.ScareEvent
-		lastAnalysisTime: time.Now(),
+		lastAnalysisTime: common.Now(),
 		positionHistory:  []entity.Vector2D{},
 		areaVisits:       make(map[string]int),
 		sectorsExplored:  make(map[string]bool),
 		sectorSize:       5.0,                                       // World unit sector size
-		heatmap:          make([][]float64, 50),                     // 50x50 heatmap
 		scareResponses:   make(map[common.ScareEventType][]float64), // Changed to use common.ScareEventType
 	}
+	analyzer.SetWorldSize(defaultWorldSize, defaultWorldSize)
+	return analyzer
+}
+
+// SetWorldSize makes the heatmap cover a world of the given size. Collected heat is discarded.
+func (a *Analyzer) SetWorldSize(width, height int) {
+	a.SetWorldArea(common.Bounds{Max: common.Vector2D{X: float64(width), Y: float64(height)}})
+}
+
+// SetWorldArea makes the heatmap cover the area, for worlds that do not start at the origin
+// or have no edges. Collected heat is discarded.
+func (a *Analyzer) SetWorldArea(area common.Bounds) {
+	a.heatmap = make([][]float64, heatmapSize)
+	for i := range a.heatmap {
+		a.heatmap[i] = make([]float64, heatmapSize)
+	}
+	a.heatOrigin = entity.FromCommonVector(area.Min)
+	a.cellWidth = (area.Max.X - area.Min.X) / heatmapSize
+	a.cellHeight = (area.Max.Y - area.Min.Y) / heatmapSize
+	a.maxHeat = 0
 }
 
 // AnalyzePlayer performs comprehensive analysis of player behavior
@@ -102,7 +147,7 @@ func (a *Analyzer) AnalyzePlayer() {
 	a.detectPatterns()
 
 	// Update last analysis time
-	a.lastAnalysisTime = time.Now()
+	a.lastAnalysisTime = common.Now()
 }
 
 // recordPlayerPosition records the current player position
@@ -230,30 +275,34 @@ func (a *Analyzer) RecordScareResponse(event common.ScareEvent, response float64
 	a.interactionAnalysis.ResponseToScareEvents[event.Type] = avgResponse
 }
 
-// updateHeatmap updates the visit heatmap
+// updateHeatmap counts a visit to the heatmap cell the player is in
 func (a *Analyzer) updateHeatmap() {
-	// Initialize heatmap if needed
-	if len(a.heatmap) == 0 || len(a.heatmap[0]) == 0 {
-		for i := range a.heatmap {
-			a.heatmap[i] = make([]float64, 50)
-		}
+	x, y, ok := a.heatCell(a.player.Position)
+	if !ok {
+		return
 	}
 
-	// Update heatmap values
-	for key, visits := range a.areaVisits {
-		var x, y int
-		fmt.Sscanf(key, "%d,%d", &x, &y)
+	a.heatmap[y][x]++
+	a.maxHeat = math.Max(a.maxHeat, a.heatmap[y][x])
+}
 
-		// Convert world coordinates to heatmap coordinates
-		heatmapX := x * 50 / 256 // Assuming 256x256 world
-		heatmapY := y * 50 / 256
+// heatCell returns the heatmap cell containing a world position
+func (a *Analyzer) heatCell(position entity.Vector2D) (int, int, bool) {
+	x := int(math.Floor((position.X - a.heatOrigin.X) / a.cellWidth))
+	y := int(math.Floor((position.Y - a.heatOrigin.Y) / a.cellHeight))
+	if x < 0 || x >= heatmapSize || y < 0 || y >= heatmapSize {
+		return 0, 0, false
+	}
+	return x, y, true
+}
 
-		// Check that coordinates are within the map
-		if heatmapX >= 0 && heatmapX < 50 && heatmapY >= 0 && heatmapY < 50 {
-			// Increase value based on number of visits
-			a.heatmap[heatmapY][heatmapX] = math.Min(1.0, float64(visits)/10.0)
-		}
+// Heat returns how much the player uses the area around a world position, from 0 (never) to 1 (most)
+func (a *Analyzer) Heat(position entity.Vector2D) float64 {
+	x, y, ok := a.heatCell(position)
+	if !ok || a.maxHeat == 0 {
+		return 0
 	}
+	return a.heatmap[y][x] / a.maxHeat
 }
 
 // findPreferredAreas finds preferred areas
@@ -322,6 +371,9 @@ func (a *Analyzer) detectPatterns() {
 	// Analyze based on movement
 	a.detectMovementPatterns()
 
+	// Analyze based on micro-behaviors
+	a.detectSignalPatterns()
+
 	// Analyze based on interactions
 	a.detectInteractionPatterns()
 
@@ -373,6 +425,43 @@ func (a *Analyzer) detectMovementPatterns() {
 	}
 }
 
+// RecordSignal records a micro-behavior recognized in the player's raw movement
+func (a *Analyzer) RecordSignal(signal BehaviorSignal) {
+	a.signals = append(a.signals, signal)
+
+	// Forget the signals that are too old to matter
+	oldest := 0
+	for oldest < len(a.signals) && signal.Time.Sub(a.signals[oldest].Time) > signalPatternWindow {
+		oldest++
+	}
+	a.signals = a.signals[oldest:]
+}
+
+// detectSignalPatterns detects patterns from repeated micro-behaviors
+func (a *Analyzer) detectSignalPatterns() {
+	counts := make(map[SignalType]int)
+	strength := make(map[SignalType]float64)
+	for _, signal := range a.signals {
+		if common.Since(signal.Time) > signalPatternWindow {
+			continue
+		}
+		counts[signal.Type]++
+		strength[signal.Type] += signal.Strength
+	}
+
+	for signalType, pattern := range signalPatterns {
+		count := counts[signalType]
+		if count < pattern.threshold {
+			continue
+		}
+		a.addPattern(PlayerPattern{
+			Name:        pattern.name,
+			Description: pattern.description,
+			Weight:      math.Min(1, 0.4+0.1*float64(count)+0.2*strength[signalType]/float64(count)),
+		})
+	}
+}
+
 // detectInteractionPatterns detects interaction patterns
 func (a *Analyzer) detectInteractionPatterns() {
 	// Interactive - interacts with the world often
@@ -469,7 +558,27 @@ func (a *Analyzer) GetTopPatterns(count int) []PlayerPattern {
 	return a.detectedPatterns[:count]
 }
 
-// GetHeatmap returns the visit heatmap
+// RouteTrust returns how familiar the player is with the area around a position,
+// from 0 (never visited) to 1 (the most repeated sector)
+func (a *Analyzer) RouteTrust(position entity.Vector2D) float64 {
+	maxVisits := 0
+	for _, count := range a.areaVisits {
+		if count > maxVisits {
+			maxVisits = count
+		}
+	}
+
+	if maxVisits == 0 {
+		return 0
+	}
+
+	sectorX := int(position.X / a.sectorSize)
+	sectorY := int(position.Y / a.sectorSize)
+
+	return float64(a.areaVisits[makeKey(sectorX, sectorY)]) / float64(maxVisits)
+}
+
+// GetHeatmap returns visit counts per heatmap cell, indexed [y][x], covering the whole world
 func (a *Analyzer) GetHeatmap() [][]float64 {
 	return a.heatmap
 }
@@ -0,0 +1,254 @@
+package ai
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"nightmare/internal/common"
+)
+
+const (
+	banditExploration = 0.4              // Weight of the UCB exploration bonus
+	banditPriorPulls  = 2.0              // A context starts from the global estimate as if it had this many pulls
+	banditUntried     = 0.5              // Reward assumed for arms that were never pulled anywhere
+	banditDarkLevel   = 0.3              // Light below this counts as dark
+	banditOpenLevel   = 0.6              // Open space above this counts as open
+	banditLongWait    = 45 * time.Second // Time since the last scare after which the player counts as settled
+)
+
+// IntensityBucket is a coarse scare intensity the bandit learns about
+type IntensityBucket int
+
+const (
+	IntensityLow    IntensityBucket = iota // Below 0.4
+	IntensityMedium                        // From 0.4 to 0.7
+	IntensityHigh                          // From 0.7
+)
+
+// String returns the name of the intensity bucket
+func (b IntensityBucket) String() string {
+	switch b {
+	case IntensityLow:
+		return "low"
+	case IntensityMedium:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
+// intensityBucket returns the bucket an intensity falls into
+func intensityBucket(intensity float64) IntensityBucket {
+	switch {
+	case intensity < 0.4:
+		return IntensityLow
+	case intensity < 0.7:
+		return IntensityMedium
+	default:
+		return IntensityHigh
+	}
+}
+
+// BanditContext is the situation a scare is chosen in
+type BanditContext struct {
+	Dark          bool // The player stands in darkness
+	Open          bool // The player is in open space
+	CreaturesNear bool // Creatures are close to the player
+	Settled       bool // The last scare was long ago
+}
+
+// String returns a readable key of the context, used in the saved model
+func (c BanditContext) String() string {
+	parts := []string{"lit", "enclosed", "alone", "alert"}
+	if c.Dark {
+		parts[0] = "dark"
+	}
+	if c.Open {
+		parts[1] = "open"
+	}
+	if c.CreaturesNear {
+		parts[2] = "creatures"
+	}
+	if c.Settled {
+		parts[3] = "settled"
+	}
+	return strings.Join(parts, ",")
+}
+
+// ScareArm is one choice of the bandit: an event type at an intensity
+type ScareArm struct {
+	Type      common.ScareEventType
+	Intensity IntensityBucket
+}
+
+// String returns a readable key of the arm, used in the saved model
+func (a ScareArm) String() string {
+	return a.Type.String() + "/" + a.Intensity.String()
+}
+
+// ArmStats accumulates the rewards of an arm
+type ArmStats struct {
+	Pulls  float64 `json:"pulls"`
+	Reward float64 `json:"reward"` // Sum of rewards
+}
+
+// Mean returns the average reward of the arm
+func (s *ArmStats) Mean() float64 {
+	if s.Pulls == 0 {
+		return banditUntried
+	}
+	return s.Reward / s.Pulls
+}
+
+// ScareBandit is a contextual UCB bandit that learns which scare works in which situation
+type ScareBandit struct {
+	Contexts map[string]map[string]*ArmStats `json:"contexts"` // Context -> arm -> stats
+	Global   map[string]*ArmStats            `json:"global"`   // Arm -> stats over all contexts
+}
+
+// ArmReport is a readable estimate of one arm in one context
+type ArmReport struct {
+	Context string
+	Arm     string
+	Pulls   float64
+	Mean    float64
+}
+
+// NewScareBandit creates a bandit that knows nothing yet
+func NewScareBandit() *ScareBandit {
+	return &ScareBandit{
+		Contexts: make(map[string]map[string]*ArmStats),
+		Global:   make(map[string]*ArmStats),
+	}
+}
+
+// estimate returns the expected reward of an arm in a context and how much it is backed by data.
+// Contexts with few pulls lean on what the arm earned everywhere else.
+func (b *ScareBandit) estimate(context BanditContext, arm ScareArm) (float64, float64) {
+	prior := banditUntried
+	if global, ok := b.Global[arm.String()]; ok {
+		prior = global.Mean()
+	}
+
+	stats, ok := b.Contexts[context.String()][arm.String()]
+	if !ok {
+		return prior, 0
+	}
+	return (stats.Reward + prior*banditPriorPulls) / (stats.Pulls + banditPriorPulls), stats.Pulls
+}
+
+// Choose picks the event type with the best upper confidence bound among arms of the given intensity.
+// Without exploration it picks the best estimate.
+func (b *ScareBandit) Choose(context BanditContext, intensity IntensityBucket, explore bool) (common.ScareEventType, float64) {
+	return b.ChooseWeighted(context, intensity, explore, nil)
+}
+
+// ChooseWeighted is Choose with the score of each event type multiplied by its weight.
+// Types weighing 0 are never chosen; a nil weight function weighs every type 1.
+func (b *ScareBandit) ChooseWeighted(context BanditContext, intensity IntensityBucket, explore bool, weight func(common.ScareEventType) float64) (common.ScareEventType, float64) {
+	total := 1.0
+	for _, stats := range b.Contexts[context.String()] {
+		total += stats.Pulls
+	}
+
+	best := common.ScareEventType(0)
+	bestScore := math.Inf(-1)
+	for i := 0; i < scareEventTypeCount; i++ {
+		arm := ScareArm{Type: common.ScareEventType(i), Intensity: intensity}
+		mean, pulls := b.estimate(context, arm)
+
+		score := mean
+		if explore {
+			score += banditExploration * math.Sqrt(math.Log(total+1)/(pulls+1))
+		}
+		if weight != nil {
+			w := weight(arm.Type)
+			if w <= 0 {
+				continue
+			}
+			score *= w
+		}
+		if score > bestScore {
+			best, bestScore = arm.Type, score
+		}
+	}
+
+	return best, bestScore
+}
+
+// Update records the reward an arm earned in a context
+func (b *ScareBandit) Update(context BanditContext, arm ScareArm, reward float64) {
+	contextStats, ok := b.Contexts[context.String()]
+	if !ok {
+		contextStats = make(map[string]*ArmStats)
+		b.Contexts[context.String()] = contextStats
+	}
+
+	for _, stats := range []*ArmStats{armStats(contextStats, arm), armStats(b.Global, arm)} {
+		stats.Pulls++
+		stats.Reward += reward
+	}
+}
+
+// armStats returns the stats of an arm, creating them on first use
+func armStats(stats map[string]*ArmStats, arm ScareArm) *ArmStats {
+	s, ok := stats[arm.String()]
+	if !ok {
+		s = &ArmStats{}
+		stats[arm.String()] = s
+	}
+	return s
+}
+
+// Decay keeps the learned averages but lowers the confidence in them, so old habits are re-tested
+func (b *ScareBandit) Decay(keep float64) {
+	scale := func(stats map[string]*ArmStats) {
+		for _, s := range stats {
+			s.Pulls *= keep
+			s.Reward *= keep
+		}
+	}
+
+	scale(b.Global)
+	for _, stats := range b.Contexts {
+		scale(stats)
+	}
+}
+
+// Report lists what the bandit has learned, best arms of each context first
+func (b *ScareBandit) Report() []ArmReport {
+	report := []ArmReport{}
+	for context, stats := range b.Contexts {
+		for arm, s := range stats {
+			report = append(report, ArmReport{Context: context, Arm: arm, Pulls: s.Pulls, Mean: s.Mean()})
+		}
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Context != report[j].Context {
+			return report[i].Context < report[j].Context
+		}
+		return report[i].Mean > report[j].Mean
+	})
+	return report
+}
+
+// banditContext describes the player's current situation
+func (d *Director) banditContext() BanditContext {
+	context := BanditContext{Settled: true}
+	if len(d.scareHistory) > 0 {
+		context.Settled = common.Since(d.scareHistory[len(d.scareHistory)-1].Timestamp) > banditLongWait
+	}
+
+	if d.observer == nil {
+		return context
+	}
+
+	observed := d.observer.GetContext()
+	context.Dark = observed.LightLevel < banditDarkLevel
+	context.Open = observed.OpenSpace > banditOpenLevel
+	context.CreaturesNear = len(observed.NearbyCreatures) > 0
+	return context
+}
@@ -0,0 +1,493 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+// SignalType is a kind of micro-behavior recognized in the player's raw movement
+type SignalType int
+
+const (
+	SignalFreeze     SignalType = iota // Stood still right after a scare
+	SignalBacktrack                    // Turned around and went back the way they came
+	SignalCircling                     // Walked in a loop
+	SignalLookAround                   // Turned frantically back and forth in place
+	SignalWallHug                      // Kept close to walls or tree lines
+	SignalSprint                       // Burst of movement much faster than usual
+)
+
+// String returns the name of the signal type
+func (s SignalType) String() string {
+	switch s {
+	case SignalFreeze:
+		return "freeze"
+	case SignalBacktrack:
+		return "backtrack"
+	case SignalCircling:
+		return "circling"
+	case SignalLookAround:
+		return "look-around"
+	case SignalWallHug:
+		return "wall-hug"
+	case SignalSprint:
+		return "sprint"
+	default:
+		return "unknown"
+	}
+}
+
+// fearful checks whether the behavior is a flight or freeze response
+func (s SignalType) fearful() bool {
+	switch s {
+	case SignalFreeze, SignalSprint, SignalBacktrack, SignalLookAround:
+		return true
+	default:
+		return false
+	}
+}
+
+// BehaviorSignal is one micro-behavior the detector recognized
+type BehaviorSignal struct {
+	Type     SignalType
+	Time     time.Time       // When the behavior was recognized
+	Position entity.Vector2D // Where the player was
+	Strength float64         // How pronounced the behavior was, from 0 to 1
+	Duration time.Duration   // How long the behavior had lasted when it was recognized
+}
+
+// String describes the signal
+func (s BehaviorSignal) String() string {
+	return fmt.Sprintf("%s %s %.2f for %.1fs at (%.0f, %.0f)",
+		s.Time.Format("15:04:05"), s.Type, s.Strength, s.Duration.Seconds(), s.Position.X, s.Position.Y)
+}
+
+// Detection settings
+const (
+	sampleWindow      = 10 * time.Second // Movement history the detector keeps
+	detectEvery       = 6                // Samples between detection passes
+	signalHistorySize = 50               // Recent signals kept for consumers
+	stillDistance     = 0.01             // Movement per sample below this counts as standing still
+	usualSpeedRate    = 0.005            // How fast the usual pace follows the player, per detection pass
+
+	freezeAfterScare = 1500 * time.Millisecond // A freeze must start this soon after a scare
+	freezeLead       = 500 * time.Millisecond  // Slow walkers may seem to stop a little before the scare
+	freezeMinimum    = time.Second             // Standing still shorter than this is not a freeze
+	freezeFull       = 3 * time.Second         // Standing still this long is a full-strength freeze
+
+	backtrackLeg   = 2 * time.Second // Length of the legs compared before and after the turn
+	backtrackAngle = 3 * math.Pi / 4 // Turning at least this much counts as going back
+	circleWindow   = 8 * time.Second // Time within which a loop must be closed
+	circleStep     = 500 * time.Millisecond
+	lookWindow     = 3 * time.Second // Time within which the player must look around
+	lookTurn       = 1.25 * math.Pi  // Total turning that counts as looking around
+	lookReversals  = 2               // Changes of turning direction needed
+	hugWindow      = 4 * time.Second
+	hugStep        = 250 * time.Millisecond
+	hugDistance    = 1.5  // Walls closer than this to either side count
+	hugShare       = 0.75 // Share of the walk that must be along a wall
+	sprintWindow   = time.Second
+	sprintRatio    = 1.8 // Pace above this multiple of the usual one is a sprint
+)
+
+// movementSample is the player's position and facing at one tick
+type movementSample struct {
+	time      time.Time
+	position  entity.Vector2D
+	direction float64
+}
+
+// BehaviorDetector recognizes micro-behaviors in the player's position and facing sampled every tick:
+// freezing after a scare, backtracking, circling, looking around, hugging walls and sprinting.
+type BehaviorDetector struct {
+	player    *entity.Player
+	world     interface{} // Optional; wall hugging needs to know which tiles are solid
+	samples   []movementSample
+	signals   []BehaviorSignal
+	listeners []func(BehaviorSignal)
+
+	usualSpeed float64                  // The player's usual pace while moving, in units per second
+	lastSignal map[SignalType]time.Time // Each behavior is reported once while it lasts
+	stillSince time.Time                // When the player last stopped moving
+	lastScare  time.Time
+	frozen     bool // A freeze was already reported for the last scare
+	count      int
+}
+
+// NewBehaviorDetector creates a detector watching the player
+func NewBehaviorDetector(player *entity.Player, world interface{}) *BehaviorDetector {
+	return &BehaviorDetector{
+		player:     player,
+		world:      world,
+		lastSignal: make(map[SignalType]time.Time),
+		stillSince: common.Now(),
+	}
+}
+
+// OnSignal registers a function called with every recognized signal
+func (b *BehaviorDetector) OnSignal(listener func(BehaviorSignal)) {
+	b.listeners = append(b.listeners, listener)
+}
+
+// NoteScare tells the detector a scare just happened, so that a freeze can be recognized
+func (b *BehaviorDetector) NoteScare(at time.Time) {
+	b.lastScare = at
+	b.frozen = false
+}
+
+// Signals returns the recently recognized signals, oldest first
+func (b *BehaviorDetector) Signals() []BehaviorSignal {
+	return append([]BehaviorSignal(nil), b.signals...)
+}
+
+// UsualSpeed returns the player's usual pace while moving
+func (b *BehaviorDetector) UsualSpeed() float64 {
+	return b.usualSpeed
+}
+
+// Update samples the player; call it every tick
+func (b *BehaviorDetector) Update() {
+	now := common.Now()
+	sample := movementSample{time: now, position: b.player.Position, direction: b.player.Direction}
+
+	if len(b.samples) > 0 && distance(sample.position, b.samples[len(b.samples)-1].position) > stillDistance {
+		b.stillSince = now
+	}
+
+	b.samples = append(b.samples, sample)
+	oldest := 0
+	for oldest < len(b.samples) && now.Sub(b.samples[oldest].time) > sampleWindow {
+		oldest++
+	}
+	b.samples = b.samples[oldest:]
+
+	b.count++
+	if b.count%detectEvery != 0 {
+		return
+	}
+
+	b.updateUsualSpeed()
+	b.detectFreeze(now)
+	b.detectBacktrack(now)
+	b.detectCircling(now)
+	b.detectLookAround(now)
+	b.detectWallHug(now)
+	b.detectSprint(now)
+}
+
+// updateUsualSpeed follows the player's pace whenever they are moving
+func (b *BehaviorDetector) updateUsualSpeed() {
+	speed := b.speed(sprintWindow)
+	if speed <= 0 {
+		return
+	}
+	if b.usualSpeed == 0 {
+		b.usualSpeed = speed
+		return
+	}
+	b.usualSpeed += (speed - b.usualSpeed) * usualSpeedRate
+}
+
+// detectFreeze recognizes the player stopping dead right after a scare
+func (b *BehaviorDetector) detectFreeze(now time.Time) {
+	if b.frozen || b.lastScare.IsZero() {
+		return
+	}
+
+	// Someone who was already standing when the scare came did not freeze
+	stopped := b.stillSince.Sub(b.lastScare)
+	if stopped < -freezeLead || stopped > freezeAfterScare {
+		if now.Sub(b.lastScare) > freezeAfterScare+freezeMinimum {
+			b.frozen = true
+		}
+		return
+	}
+
+	still := now.Sub(b.stillSince)
+	if still < freezeMinimum {
+		return
+	}
+
+	b.frozen = true
+	b.emit(SignalFreeze, clamp01(still.Seconds()/freezeFull.Seconds()), still)
+}
+
+// detectBacktrack recognizes the player turning around and going back
+func (b *BehaviorDetector) detectBacktrack(now time.Time) {
+	first := b.window(now.Add(-2*backtrackLeg), now.Add(-backtrackLeg))
+	second := b.window(now.Add(-backtrackLeg), now)
+	if len(first) < 2 || len(second) < 2 {
+		return
+	}
+
+	// Both legs must be real walks, not shuffling on the spot
+	minLeg := math.Max(entity.MoveSpeed, b.usualSpeed*backtrackLeg.Seconds()*0.5)
+	out, back := displacement(first), displacement(second)
+	if length(out) < minLeg || length(back) < minLeg {
+		return
+	}
+
+	angle := math.Abs(math.Remainder(math.Atan2(back.Y, back.X)-math.Atan2(out.Y, out.X), 2*math.Pi))
+	if angle < backtrackAngle {
+		return
+	}
+	b.emitOnce(now, SignalBacktrack, (angle-backtrackAngle)/(math.Pi-backtrackAngle), 2*backtrackLeg, 2*backtrackLeg)
+}
+
+// detectCircling recognizes the player walking in a loop
+func (b *BehaviorDetector) detectCircling(now time.Time) {
+	points := b.every(b.window(now.Add(-circleWindow), now), circleStep)
+	if len(points) < 4 {
+		return
+	}
+
+	// Sum how much the walking direction turned, in one direction or the other
+	turned, walked := 0.0, 0.0
+	var previous *entity.Vector2D
+	for i := 1; i < len(points); i++ {
+		step := entity.Vector2D{X: points[i].position.X - points[i-1].position.X, Y: points[i].position.Y - points[i-1].position.Y}
+		if length(step) < stillDistance {
+			continue
+		}
+		walked += length(step)
+		if previous != nil {
+			turned += math.Remainder(math.Atan2(step.Y, step.X)-math.Atan2(previous.Y, previous.X), 2*math.Pi)
+		}
+		previous = &step
+	}
+
+	// A loop turns all the way round and ends near where it started
+	net := distance(points[0].position, points[len(points)-1].position)
+	if math.Abs(turned) < 1.8*math.Pi || net > walked*0.5 {
+		return
+	}
+	b.emitOnce(now, SignalCircling, clamp01(math.Abs(turned)/(4*math.Pi)), circleWindow, circleWindow)
+}
+
+// detectLookAround recognizes frantic turning back and forth while staying in place
+func (b *BehaviorDetector) detectLookAround(now time.Time) {
+	samples := b.window(now.Add(-lookWindow), now)
+	if len(samples) < 2 {
+		return
+	}
+
+	turned, reversals, lastSign := 0.0, 0, 0.0
+	for i := 1; i < len(samples); i++ {
+		delta := math.Remainder(samples[i].direction-samples[i-1].direction, 2*math.Pi)
+		if delta == 0 {
+			continue
+		}
+		turned += math.Abs(delta)
+		sign := math.Copysign(1, delta)
+		if lastSign != 0 && sign != lastSign {
+			reversals++
+		}
+		lastSign = sign
+	}
+
+	if turned < lookTurn || reversals < lookReversals {
+		return
+	}
+	if b.usualSpeed > 0 && pathLength(samples) > b.usualSpeed*lookWindow.Seconds()*0.5 {
+		return
+	}
+	b.emitOnce(now, SignalLookAround, clamp01(turned/(3*math.Pi)), lookWindow, lookWindow)
+}
+
+// detectWallHug recognizes the player keeping close to walls or tree lines
+func (b *BehaviorDetector) detectWallHug(now time.Time) {
+	worldObj, ok := b.world.(interface {
+		IsBlocked(common.Vector2D) bool
+	})
+	if !ok {
+		return
+	}
+
+	points := b.every(b.window(now.Add(-hugWindow), now), hugStep)
+	moving, hugging := 0, 0
+	for i := 1; i < len(points); i++ {
+		step := entity.Vector2D{X: points[i].position.X - points[i-1].position.X, Y: points[i].position.Y - points[i-1].position.Y}
+		if length(step) < stillDistance {
+			continue
+		}
+		moving++
+
+		heading := math.Atan2(step.Y, step.X)
+		for _, side := range []float64{-math.Pi / 2, math.Pi / 2} {
+			probe := common.Vector2D{
+				X: points[i].position.X + math.Cos(heading+side)*hugDistance,
+				Y: points[i].position.Y + math.Sin(heading+side)*hugDistance,
+			}
+			if worldObj.IsBlocked(probe) {
+				hugging++
+				break
+			}
+		}
+	}
+
+	if moving < int(hugWindow/hugStep)/2 {
+		return
+	}
+	share := float64(hugging) / float64(moving)
+	if share < hugShare {
+		return
+	}
+	b.emitOnce(now, SignalWallHug, share, hugWindow, hugWindow)
+}
+
+// detectSprint recognizes bursts of movement much faster than the player's usual pace
+func (b *BehaviorDetector) detectSprint(now time.Time) {
+	if b.usualSpeed <= 0 {
+		return
+	}
+	ratio := b.speed(sprintWindow) / b.usualSpeed
+	if ratio < sprintRatio {
+		return
+	}
+	b.emitOnce(now, SignalSprint, clamp01(ratio/(2*sprintRatio)), sprintWindow, 3*sprintWindow)
+}
+
+// emitOnce reports a signal unless the same behavior was reported within the cooldown
+func (b *BehaviorDetector) emitOnce(now time.Time, signalType SignalType, strength float64, duration, cooldown time.Duration) {
+	if last, ok := b.lastSignal[signalType]; ok && now.Sub(last) < cooldown {
+		return
+	}
+	b.emit(signalType, strength, duration)
+}
+
+// emit reports a signal to the listeners
+func (b *BehaviorDetector) emit(signalType SignalType, strength float64, duration time.Duration) {
+	signal := BehaviorSignal{
+		Type:     signalType,
+		Time:     common.Now(),
+		Position: b.player.Position,
+		Strength: clamp01(strength),
+		Duration: duration,
+	}
+	b.lastSignal[signalType] = signal.Time
+
+	b.signals = append(b.signals, signal)
+	if len(b.signals) > signalHistorySize {
+		b.signals = b.signals[len(b.signals)-signalHistorySize:]
+	}
+
+	for _, listener := range b.listeners {
+		listener(signal)
+	}
+}
+
+// window returns the samples taken in the time range
+func (b *BehaviorDetector) window(from, to time.Time) []movementSample {
+	start := len(b.samples)
+	for start > 0 && !b.samples[start-1].time.Before(from) {
+		start--
+	}
+	end := start
+	for end < len(b.samples) && !b.samples[end].time.After(to) {
+		end++
+	}
+	return b.samples[start:end]
+}
+
+// every thins samples out to at most one per step
+func (b *BehaviorDetector) every(samples []movementSample, step time.Duration) []movementSample {
+	thinned := []movementSample{}
+	for _, sample := range samples {
+		if len(thinned) == 0 || sample.time.Sub(thinned[len(thinned)-1].time) >= step {
+			thinned = append(thinned, sample)
+		}
+	}
+	return thinned
+}
+
+// speed returns the player's pace over the last stretch of time, in units per second
+func (b *BehaviorDetector) speed(window time.Duration) float64 {
+	now := common.Now()
+	return pathLength(b.window(now.Add(-window), now)) / window.Seconds()
+}
+
+// pathLength returns the distance walked through the samples
+func pathLength(samples []movementSample) float64 {
+	total := 0.0
+	for i := 1; i < len(samples); i++ {
+		total += distance(samples[i].position, samples[i-1].position)
+	}
+	return total
+}
+
+// displacement returns the vector from the first sample to the last
+func displacement(samples []movementSample) entity.Vector2D {
+	first, last := samples[0].position, samples[len(samples)-1].position
+	return entity.Vector2D{X: last.X - first.X, Y: last.Y - first.Y}
+}
+
+// length returns the length of a vector
+func length(v entity.Vector2D) float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+// signalActions maps micro-behaviors onto the actions the observer profiles the player by
+var signalActions = map[SignalType]ActionType{
+	SignalFreeze:     ActionFreeze,
+	SignalBacktrack:  ActionRetreat,
+	SignalCircling:   ActionInvestigate,
+	SignalLookAround: ActionInvestigate,
+	SignalWallHug:    ActionHide,
+	SignalSprint:     ActionRun,
+}
+
+// SetBehaviorDetector makes the director, its analyzer and its observer learn from micro-behaviors
+func (d *Director) SetBehaviorDetector(detector *BehaviorDetector) {
+	d.detector = detector
+	detector.OnSignal(d.receiveSignal)
+}
+
+// receiveSignal passes a recognized micro-behavior on to the analyzer and the observer
+func (d *Director) receiveSignal(signal BehaviorSignal) {
+	if d.analyzer != nil {
+		d.analyzer.RecordSignal(signal)
+	}
+	if d.observer != nil {
+		d.observer.RecordSignal(signal)
+	}
+}
+
+// signalReaction returns the strongest flight or freeze behavior recognized in the time range
+func (d *Director) signalReaction(from, to time.Time) float64 {
+	if d.detector == nil {
+		return 0
+	}
+
+	reaction := 0.0
+	for _, signal := range d.detector.Signals() {
+		if signal.Time.Before(from) || signal.Time.After(to) {
+			continue
+		}
+		if signal.Type.fearful() {
+			reaction = math.Max(reaction, signal.Strength)
+		}
+	}
+	return reaction
+}
+
+// RecordSignal records a micro-behavior as a player action. Fearful behaviors also
+// count towards the fear of the place the player is in.
+func (o *ObserverSystem) RecordSignal(signal BehaviorSignal) {
+	if signal.Type.fearful() {
+		o.noteReaction(signal.Strength)
+	}
+
+	o.addPlayerAction(PlayerAction{
+		Type:      signalActions[signal.Type],
+		Position:  signal.Position,
+		Timestamp: signal.Time,
+		Context: map[string]interface{}{
+			"signal":   signal.Type.String(),
+			"strength": signal.Strength,
+		},
+	})
+}
@@ -0,0 +1,150 @@
+package ai
+
+import (
+	"math"
+	"time"
+
+	"nightmare/internal/biometric"
+	"nightmare/internal/common"
+)
+
+const (
+	restingRecovery = 10 * time.Second  // How fast the resting rate follows a calmer heart
+	restingDrift    = 120 * time.Second // How fast the resting rate follows a faster heart
+	arousalRange    = 40.0              // Beats per minute above resting that count as full arousal
+	heartWindow     = 8 * time.Second   // How long after a scare the heart's reaction is watched
+	heartFearWeight = 0.5               // Share of the heart's reaction in the strength of a fear response
+)
+
+// HeartMonitor turns raw heart rate into arousal above the player's own resting rate.
+// The resting rate is learned as the game goes: it drops quickly to a calmer heart
+// and rises only slowly, so that a long chase does not become the new normal.
+type HeartMonitor struct {
+	source     biometric.BiometricSource
+	rate       float64
+	resting    float64
+	fresh      bool
+	lastUpdate time.Time
+}
+
+// NewHeartMonitor creates a monitor reading the source
+func NewHeartMonitor(source biometric.BiometricSource) *HeartMonitor {
+	return &HeartMonitor{source: source, lastUpdate: common.Now()}
+}
+
+// Update takes a new reading; call it once per frame
+func (m *HeartMonitor) Update() {
+	now := common.Now()
+	elapsed := now.Sub(m.lastUpdate)
+	m.lastUpdate = now
+
+	bpm, ok := m.source.HeartRate()
+	m.fresh = ok
+	if !ok {
+		return
+	}
+	m.rate = bpm
+
+	if m.resting == 0 {
+		m.resting = bpm
+		return
+	}
+
+	window := restingDrift
+	if bpm < m.resting {
+		window = restingRecovery
+	}
+	m.resting += (bpm - m.resting) * (1 - math.Exp(-float64(elapsed)/float64(window)))
+}
+
+// Rate returns the latest heart rate and whether the signal is present
+func (m *HeartMonitor) Rate() (float64, bool) {
+	return m.rate, m.fresh
+}
+
+// Resting returns the learned resting heart rate
+func (m *HeartMonitor) Resting() float64 {
+	return m.resting
+}
+
+// Arousal returns how far the heart races above resting, from 0 to 1; 0 without a signal
+func (m *HeartMonitor) Arousal() float64 {
+	if !m.fresh {
+		return 0
+	}
+	return clamp01((m.rate - m.resting) / arousalRange)
+}
+
+// SetHeartMonitor makes the director count a racing heart as stress
+func (d *Director) SetHeartMonitor(monitor *HeartMonitor) {
+	d.heart = monitor
+}
+
+// heartStress returns the stress the player's heart shows
+func (d *Director) heartStress() float64 {
+	if d.heart == nil {
+		return 0
+	}
+	return d.heart.Arousal()
+}
+
+// heartResponse is a fear response waiting for the heart to react
+type heartResponse struct {
+	response FearResponse
+	before   float64 // Heart rate when the scare happened
+	peak     float64 // Highest heart rate since
+	until    time.Time
+}
+
+// SetHeartMonitor makes the observer score fear responses by the heart's reaction
+func (o *ObserverSystem) SetHeartMonitor(monitor *HeartMonitor) {
+	o.heart = monitor
+}
+
+// watchHeart holds a fear response back until the heart has had time to react.
+// Returns false if there is no heart rate signal.
+func (o *ObserverSystem) watchHeart(response FearResponse) bool {
+	if o.heart == nil {
+		return false
+	}
+	rate, ok := o.heart.Rate()
+	if !ok {
+		return false
+	}
+
+	o.heartResponses = append(o.heartResponses, heartResponse{
+		response: response,
+		before:   rate,
+		peak:     rate,
+		until:    common.Now().Add(heartWindow),
+	})
+	return true
+}
+
+// updateHeartResponses records the fear responses whose heart reaction has been measured
+func (o *ObserverSystem) updateHeartResponses() {
+	if len(o.heartResponses) == 0 {
+		return
+	}
+
+	rate, ok := o.heart.Rate()
+	now := common.Now()
+
+	waiting := o.heartResponses[:0]
+	for _, pending := range o.heartResponses {
+		if ok {
+			pending.peak = math.Max(pending.peak, rate)
+		}
+		if now.Before(pending.until) {
+			waiting = append(waiting, pending)
+			continue
+		}
+
+		response := pending.response
+		response.HeartRateChange = pending.peak - pending.before
+		heartFear := clamp01(response.HeartRateChange / arousalRange)
+		response.StrengthOfFear = response.StrengthOfFear*(1-heartFearWeight) + heartFear*heartFearWeight
+		o.recordFearResponse(response)
+	}
+	o.heartResponses = waiting
+}
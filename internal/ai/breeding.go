@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"sort"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+const (
+	breedChance       = 0.4  // Chance that a creature appearance uses a bred genome
+	breedMutationRate = 0.15 // Mutation rate applied to bred genomes
+	keepScareScore    = 40.0 // Creatures that took this much sanity are kept for reuse
+	maxKeptGenomes    = 8    // Maximum number of kept genomes
+)
+
+// scoredGenome is a genome together with how much it scared the player
+type scoredGenome struct {
+	genome *entity.Genome
+	score  float64
+}
+
+// collectGenomes records the genomes of creatures that have scared the player
+func (d *Director) collectGenomes() {
+	worldObj, ok := d.world.(interface {
+		Creatures() []*entity.Creature
+	})
+	if !ok {
+		return
+	}
+
+	// Creatures that died or left the world no longer breed; the scariest are already kept
+	living := make(map[int]bool)
+	for _, creature := range worldObj.Creatures() {
+		if !creature.IsDead() {
+			living[creature.ID] = true
+		}
+	}
+	for id := range d.genePool {
+		if !living[id] {
+			delete(d.genePool, id)
+		}
+	}
+
+	for _, creature := range worldObj.Creatures() {
+		if creature.Genome == nil || creature.ScareScore <= 0 || creature.IsDead() {
+			continue
+		}
+
+		// A creature is recorded once and its score keeps growing while it lives
+		previous, seen := d.genePool[creature.ID]
+		d.genePool[creature.ID] = scoredGenome{genome: creature.Genome, score: creature.ScareScore}
+
+		if creature.ScareScore >= keepScareScore && (!seen || previous.score < keepScareScore) {
+			d.KeepGenome(creature.Genome)
+		}
+	}
+}
+
+// KeepGenome stores a genome so that it can be bred from and reused later
+func (d *Director) KeepGenome(genome *entity.Genome) {
+	d.keptGenomes = append(d.keptGenomes, genome.Clone())
+	if len(d.keptGenomes) > maxKeptGenomes {
+		d.keptGenomes = d.keptGenomes[len(d.keptGenomes)-maxKeptGenomes:]
+	}
+}
+
+// KeptGenomes returns the genomes of the scariest creatures so far
+func (d *Director) KeptGenomes() []*entity.Genome {
+	return d.keptGenomes
+}
+
+// breedGenome breeds a new genome from the creatures that scared the player the most.
+// Returns nil if no creature has scared the player yet.
+func (d *Director) breedGenome() *entity.Genome {
+	// Creatures are taken in the order of their IDs, so equal scores rank the same in every run
+	ids := make([]int, 0, len(d.genePool))
+	for id := range d.genePool {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	candidates := []scoredGenome{}
+	for _, id := range ids {
+		candidates = append(candidates, d.genePool[id])
+	}
+	for _, genome := range d.keptGenomes {
+		candidates = append(candidates, scoredGenome{genome: genome, score: keepScareScore})
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	var child *entity.Genome
+	if len(candidates) == 1 {
+		child = candidates[0].genome.Clone()
+	} else {
+		// Cross the scariest creature with one of the next best
+		other := candidates[1+common.Rand().Intn(min(3, len(candidates)-1))]
+		child = entity.Crossover(candidates[0].genome, other.genome)
+	}
+
+	child.Mutate(breedMutationRate)
+	return child
+}
+
+// spawnBredCreature tries to spawn a bred creature for a creature appearance event
+func (d *Director) spawnBredCreature(event common.ScareEvent) bool {
+	if common.Rand().Float64() >= breedChance {
+		return false
+	}
+
+	worldObj, ok := d.world.(interface {
+		RequestGenomeSpawn(*entity.Genome, common.Vector2D) bool
+	})
+	if !ok {
+		return false
+	}
+
+	genome := d.breedGenome()
+	if genome == nil {
+		return false
+	}
+
+	return worldObj.RequestGenomeSpawn(genome, event.Position)
+}
@@ -0,0 +1,127 @@
+package ai
+
+import (
+	"math"
+	"time"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+// Observation context settings
+const (
+	contextRadius   = 8.0  // Radius of the area whose openness is measured
+	exitRadius      = 12   // Paths are counted where they cross a square this far from the player
+	creatureRadius  = 20.0 // Creatures closer than this count as nearby
+	tightSpaceLevel = 0.5  // Open space below this counts as a tight space
+	openSpaceLevel  = 0.85 // Open space above this counts as open ground
+	darkLightLevel  = 0.3  // Light below this counts as darkness
+	lightLookAhead  = 4.0  // Light is sampled this far ahead of the player, where the flashlight beam lands
+
+	minExposure = 30 * time.Second // Time needed both inside and outside a place before its fear is measured
+)
+
+// situationalFears are fears of places rather than of scares, with the test for being in such a place
+var situationalFears = map[FearType]func(ObservationContext) bool{
+	FearClaustrophobia: func(c ObservationContext) bool { return c.OpenSpace < tightSpaceLevel },
+	FearOpenSpaces:     func(c ObservationContext) bool { return c.OpenSpace > openSpaceLevel },
+	FearDarkness:       func(c ObservationContext) bool { return c.LightLevel < darkLightLevel },
+}
+
+// situationExposure is how long the player spent inside and outside a kind of place,
+// and how strongly they reacted there
+type situationExposure struct {
+	inside, outside                   time.Duration
+	reactionsInside, reactionsOutside float64
+}
+
+// observeWorld fills the context with the surroundings of the player
+func (o *ObserverSystem) observeWorld() {
+	if o.player == nil || o.director == nil {
+		return
+	}
+	world := o.director.world
+	position := o.player.Position.ToCommonVector()
+
+	// The flashlight always lights the player up, so the light is sampled where the player is heading:
+	// it is dark there when trees swallow the beam or the flashlight fails
+	ahead := common.Vector2D{
+		X: position.X + math.Cos(o.player.Direction)*lightLookAhead,
+		Y: position.Y + math.Sin(o.player.Direction)*lightLookAhead,
+	}
+	if lit, ok := world.(interface {
+		LightAt(common.Vector2D) (float64, bool)
+	}); ok {
+		if level, ok := lit.LightAt(ahead); ok {
+			o.context.LightLevel = level
+		}
+	}
+
+	if terrain, ok := world.(interface {
+		OpenSpaceAt(common.Vector2D, float64) float64
+		ExitsAround(common.Vector2D, int) int
+	}); ok {
+		o.context.OpenSpace = terrain.OpenSpaceAt(position, contextRadius)
+		o.context.NearbyExits = terrain.ExitsAround(position, exitRadius)
+	}
+
+	if population, ok := world.(interface {
+		CreaturesNear(common.Vector2D, float64) []*entity.Creature
+	}); ok {
+		o.context.NearbyCreatures = population.CreaturesNear(position, creatureRadius)
+	}
+}
+
+// trackExposure adds the time since the previous update to the places the player is in
+func (o *ObserverSystem) trackExposure(elapsed time.Duration) {
+	for fearType, inside := range situationalFears {
+		exposure := o.exposureTo(fearType)
+		if inside(o.context) {
+			exposure.inside += elapsed
+		} else {
+			exposure.outside += elapsed
+		}
+	}
+}
+
+// noteReaction credits a fearful reaction to the places the player is in
+func (o *ObserverSystem) noteReaction(strength float64) {
+	for fearType, inside := range situationalFears {
+		exposure := o.exposureTo(fearType)
+		if inside(o.context) {
+			exposure.reactionsInside += strength
+		} else {
+			exposure.reactionsOutside += strength
+		}
+	}
+}
+
+// measureSituationalFears compares how strongly the player reacts inside each kind of place
+// and elsewhere. A player who reacts only there scores 1, one who reacts only elsewhere 0.
+func (o *ObserverSystem) measureSituationalFears() {
+	for fearType := range situationalFears {
+		exposure := o.exposureTo(fearType)
+		if exposure.inside < minExposure || exposure.outside < minExposure {
+			continue
+		}
+
+		rateInside := exposure.reactionsInside / exposure.inside.Minutes()
+		rateOutside := exposure.reactionsOutside / exposure.outside.Minutes()
+		if rateInside+rateOutside == 0 {
+			continue
+		}
+
+		measured := rateInside / (rateInside + rateOutside)
+		o.fearProfile[fearType] = o.fearProfile[fearType]*0.7 + measured*0.3
+	}
+}
+
+// exposureTo returns the exposure record of a situational fear, creating it on first use
+func (o *ObserverSystem) exposureTo(fearType FearType) *situationExposure {
+	exposure, ok := o.exposure[fearType]
+	if !ok {
+		exposure = &situationExposure{}
+		o.exposure[fearType] = exposure
+	}
+	return exposure
+}
@@ -0,0 +1,174 @@
+package ai
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"nightmare/internal/common"
+)
+
+const (
+	decisionLogSize = 100 // Decisions kept in memory for the overlay
+	historySize     = 240 // Tension and mood samples kept for graphs, two minutes at one sample per pacing update
+)
+
+// Decision is one explained choice of the director
+type Decision struct {
+	Time    time.Time
+	Message string
+}
+
+// String formats the decision as a log line
+func (d Decision) String() string {
+	return d.Time.Format("15:04:05") + " " + d.Message
+}
+
+// TensionSample is the director's tension and mood at one moment
+type TensionSample struct {
+	Tension float64
+	Mood    float64
+}
+
+// DebugState is a snapshot of everything the director bases its decisions on
+type DebugState struct {
+	Pacing          PacingState
+	History         []TensionSample
+	Effectiveness   map[common.ScareEventType]float64
+	FearProfile     map[FearType]float64
+	ReactorProfile  map[ReactorType]float64
+	Recommendations []ScareRecommendation
+	Decisions       []Decision
+	Personality     string           // Name of the director's personality
+	Sequence        string           // Name of the running authored sequence, if any
+	Context         string           // Situation the bandit currently sees
+	Avoided         map[string]int   // Fairness violations avoided, by rule
+	HeartRate       float64          // Player's heart rate, 0 without a signal
+	RestingRate     float64          // Player's learned resting heart rate
+	Signals         []BehaviorSignal // Micro-behaviors recognized recently, oldest first
+	Bandit          []ArmReport      // What the bandit learned in the current context, best arms first
+}
+
+// DefaultDecisionLogPath returns the location of the director's decision log
+func DefaultDecisionLogPath() string {
+	return filepath.Join(filepath.Dir(DefaultProfilePath()), "director.log")
+}
+
+// OpenDecisionLog opens the decision log for appending
+func OpenDecisionLog(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+}
+
+// SetDecisionOutput makes the director also write every decision to w
+func (d *Director) SetDecisionOutput(w io.Writer) {
+	d.decisionOutput = w
+}
+
+// logDecision records why the director did something
+func (d *Director) logDecision(format string, args ...interface{}) {
+	decision := Decision{Time: common.Now(), Message: fmt.Sprintf(format, args...)}
+
+	d.decisions = append(d.decisions, decision)
+	if len(d.decisions) > decisionLogSize {
+		d.decisions = d.decisions[len(d.decisions)-decisionLogSize:]
+	}
+
+	if d.decisionOutput != nil {
+		fmt.Fprintln(d.decisionOutput, decision)
+	}
+}
+
+// recordHistory samples tension and mood for the overlay graphs
+func (d *Director) recordHistory() {
+	d.history = append(d.history, TensionSample{Tension: d.tension, Mood: d.mood})
+	if len(d.history) > historySize {
+		d.history = d.history[len(d.history)-historySize:]
+	}
+}
+
+// explainScare logs why a scare was chosen
+func (d *Director) explainScare(scare common.ScareEvent, chosen common.ScareEventType) {
+	since := "first scare"
+	if len(d.scareHistory) > 0 {
+		since = fmt.Sprintf("%.0fs since last scare", common.Since(d.scareHistory[len(d.scareHistory)-1].Timestamp).Seconds())
+	}
+
+	context := d.banditContext()
+	arm := ScareArm{Type: scare.Type, Intensity: intensityBucket(scare.Intensity)}
+	expected, pulls := d.bandit.estimate(context, arm)
+
+	reason := d.phase.String()
+	if scare.Type != chosen {
+		reason = "observer recommended over " + chosen.String()
+	}
+
+	d.logDecision("chose %s: expected %.2f over %.0f pulls in %s, %s, %s",
+		arm, expected, pulls, context, since, reason)
+}
+
+// explainPlacement logs why a spot was chosen for a scare
+func (d *Director) explainPlacement(spot ambushSpot, reveal bool) {
+	kind := "ambush"
+	if reveal {
+		kind = "reveal"
+	}
+	d.logDecision("%s at (%.0f, %.0f): route %.2f, path %.2f, chokepoint %.2f, beam edge %.0f, anchor %.2f",
+		kind, spot.position.X, spot.position.Y, spot.heat, spot.path, spot.choke, spot.edge, spot.anchor)
+}
+
+// DebugState captures the director's current state for the debug overlay
+func (d *Director) DebugState() DebugState {
+	state := DebugState{
+		Pacing:         d.Pacing(),
+		History:        append([]TensionSample(nil), d.history...),
+		Effectiveness:  make(map[common.ScareEventType]float64),
+		FearProfile:    make(map[FearType]float64),
+		ReactorProfile: make(map[ReactorType]float64),
+		Decisions:      append([]Decision(nil), d.decisions...),
+		Context:        d.banditContext().String(),
+		Personality:    d.personality.Name,
+		Avoided:        d.AvoidedViolations(),
+	}
+
+	if d.detector != nil {
+		state.Signals = d.detector.Signals()
+	}
+
+	if d.heart != nil {
+		if rate, ok := d.heart.Rate(); ok {
+			state.HeartRate = rate
+		}
+		state.RestingRate = d.heart.Resting()
+	}
+
+	for eventType, value := range d.scareEffectiveness {
+		state.Effectiveness[eventType] = value
+	}
+
+	if d.observer != nil {
+		for fearType, value := range d.observer.GetPlayerFearProfile() {
+			state.FearProfile[fearType] = value
+		}
+		for reactorType, value := range d.observer.GetPlayerReactorProfile() {
+			state.ReactorProfile[reactorType] = value
+		}
+		state.Recommendations = d.observer.GetScareRecommendations(patternCount)
+	}
+
+	if d.running != nil {
+		state.Sequence = d.running.sequence.Name
+	}
+
+	for _, arm := range d.bandit.Report() {
+		if arm.Context == state.Context {
+			state.Bandit = append(state.Bandit, arm)
+		}
+	}
+
+	return state
+}
@@ -1,12 +1,13 @@
 package ai
 
 import (
+	"io"
 	"math"
-	"math/rand"
 	"time"
 
 	"nightmare/internal/common"
 	"nightmare/internal/entity"
+	"nightmare/internal/event"
 )
 
 // Using ScareEvent from common package
@@ -24,18 +25,48 @@ type BehaviorPattern struct {
 // Director represents the AI director that manages game events
 type Director struct {
 	player             *entity.Player
-	world              interface{} // Using interface to avoid direct import of world
+	world              interface{}         // Using interface to avoid direct import of world
+	analyzer           *Analyzer           // Optional source of movement statistics
+	observer           *ObserverSystem     // Optional source of scare recommendations
+	events             *event.EventManager // Scare events are reported here for observers
+	presenter          interface{}         // Plays sensory scares: sounds, screen effects, hallucinations
 	playerBehavior     BehaviorPattern
 	scareHistory       []common.ScareEvent
 	scareEffectiveness map[common.ScareEventType]float64 // Effectiveness of different scare types
+	pendingScares      []scareMeasurement                // Scares whose effect is still being measured
 	lastAnalysisTime   time.Time
-	mood               float64 // General "mood" of the director from 0 (calm) to 1 (aggressive)
-	tension            float64 // Current tension level from 0 to 1
+	mood               float64              // General "mood" of the director from 0 (calm) to 1 (aggressive)
+	tension            float64              // Current tension level from 0 to 1
+	genePool           map[int]scoredGenome // Genomes of creatures that scared the player, by creature ID
+	keptGenomes        []*entity.Genome     // Genomes of the scariest creatures, kept for reuse
+	pacing             PacingCurve          // Pacing cycle for the current difficulty
+	phase              PacingPhase          // Current pacing phase
+	phaseStart         time.Time
+	stress             float64           // Measured player stress from 0 to 1
+	lastSanity         float64           // Player's sanity at the previous pacing update
+	lastHealth         float64           // Player's health at the previous pacing update
+	sequences          []*ScareSequence  // Authored scare sequences
+	running            *runningSequence  // Sequence currently playing, or nil
+	lastZone           string            // Zone the player was in at the previous sequence update
+	pickedUp           []string          // Items picked up since the previous sequence update
+	decisions          []Decision        // Recent decisions, explained
+	decisionOutput     io.Writer         // Optional file every decision is also written to
+	history            []TensionSample   // Tension and mood over time
+	bandit             *ScareBandit      // Learns which scare works in which situation
+	fairness           FairnessRules     // Guarantees the director never breaks
+	graceStart         time.Time         // Start of the grace period after loading or respawning
+	lastDamageScare    time.Time         // When a creature last appeared for real
+	avoided            map[string]int    // Fairness violations avoided, by rule
+	heart              *HeartMonitor     // Player's heart rate, if a sensor is connected
+	detector           *BehaviorDetector // Recognizes micro-behaviors in the player's movement
+	personality        Personality       // Style of directing layered over the difficulty
+	curve              PacingCurve       // Pacing curve of the difficulty, before the personality reshapes it
+	stalker            *entity.Creature  // Creature hunting the player for a personality with a stalker
 }
 
 // NewDirector creates a new AI director
 func NewDirector(player *entity.Player, world interface{}) *Director {
-	return &Director{
+	d := &Director{
 		player: player,
 		world:  world,
 		playerBehavior: BehaviorPattern{
@@ -47,10 +78,30 @@ func NewDirector(player *entity.Player, world interface{}) *Director {
 		},
 		scareHistory:       []common.ScareEvent{},
 		scareEffectiveness: make(map[common.ScareEventType]float64),
-		lastAnalysisTime:   time.Now(),
+		pendingScares:      []scareMeasurement{},
+		lastAnalysisTime:   common.Now(),
 		mood:               0.3, // Initial mood
 		tension:            0.1, // Initial tension
+		genePool:           make(map[int]scoredGenome),
+		keptGenomes:        []*entity.Genome{},
+		pacing:             PacingCurveFor(DifficultyNormal),
+		curve:              PacingCurveFor(DifficultyNormal),
+		personality:        DefaultPersonality(),
+		phase:              PhaseBuildUp,
+		phaseStart:         common.Now(),
+		lastSanity:         player.Sanity,
+		lastHealth:         player.Health,
+		bandit:             NewScareBandit(),
+		fairness:           DefaultFairnessRules(),
+		avoided:            make(map[string]int),
 	}
+	d.StartGrace()
+	return d
+}
+
+// SetAnalyzer sets the analyzer used for placement decisions
+func (d *Director) SetAnalyzer(analyzer *Analyzer) {
+	d.analyzer = analyzer
 }
 
 // AnalyzePlayerBehavior analyzes player behavior
@@ -60,6 +111,17 @@ func (d *Director) AnalyzePlayerBehavior() {
 		return
 	}
 
+	// Keep the long-term movement statistics up to date
+	if d.analyzer != nil {
+		d.analyzer.AnalyzePlayer()
+	}
+
+	// Use what the analyzer and observer learned about the player
+	d.updateBehaviorFromObservations()
+
+	// Remember which creatures scared the player
+	d.collectGenomes()
+
 	// Analyze only logs since the last analysis
 	recentLogs := []entity.PlayerActionRecord{}
 	for _, log := range d.player.ActionLog {
@@ -68,25 +130,22 @@ func (d *Director) AnalyzePlayerBehavior() {
 		}
 	}
 
-	d.lastAnalysisTime = time.Now()
-
-	// If there are no new logs, do nothing
-	if len(recentLogs) == 0 {
-		return
-	}
+	d.lastAnalysisTime = common.Now()
 
 	// Movement analysis
-	moveCount := 0
-	for _, log := range recentLogs {
-		if log.Action == entity.ActionMove {
-			moveCount++
+	if len(recentLogs) > 0 {
+		moveCount := 0
+		for _, log := range recentLogs {
+			if log.Action == entity.ActionMove {
+				moveCount++
+			}
 		}
-	}
 
-	movementRatio := float64(moveCount) / float64(len(recentLogs))
+		movementRatio := float64(moveCount) / float64(len(recentLogs))
 
-	// Update movement preferences (with inertia)
-	d.playerBehavior.MovementPreference = d.playerBehavior.MovementPreference*0.8 + movementRatio*0.2
+		// Update movement preferences (with inertia)
+		d.playerBehavior.MovementPreference = d.playerBehavior.MovementPreference*0.8 + movementRatio*0.2
+	}
 
 	// Analyze exploration (how much the player deviates from the direct path)
 	// This is a more complex analysis that we'll simplify for this example
@@ -97,16 +156,16 @@ func (d *Director) AnalyzePlayerBehavior() {
 	// Analyze the effectiveness of past attempts to scare
 	d.analyzeScareEffectiveness()
 
-	// Update the director's mood and tension level
+	// Advance the pacing cycle, which drives mood and tension
 	d.updateMoodAndTension()
 }
 
 // AdjustWorld modifies the world based on analysis of player behavior
 func (d *Director) AdjustWorld() {
-	// Decide whether to create a scare event
-	if d.shouldCreateScareEvent() {
-		event := d.createScareEvent()
-		d.executeScareEvent(event)
+	// Decide whether to create a scare event; authored sequences take precedence
+	if !d.SequenceRunning() && d.shouldCreateScareEvent() {
+		event, context := d.createScareEvent()
+		d.executeScareEvent(event, context)
 	}
 
 	// Modify the surrounding world
@@ -118,84 +177,134 @@ func (d *Director) AdjustWorld() {
 
 // shouldCreateScareEvent determines if a scare event should be created
 func (d *Director) shouldCreateScareEvent() bool {
-	// Base chance based on tension
-	baseChance := d.tension * 0.1
+	// Quiet windows are guaranteed to the player
+	if d.InQuietWindow() {
+		return false
+	}
+
+	// Base chance depends on the pacing phase and tension
+	settings := d.phaseSettings()
+	baseChance := settings.ScareChance * (0.5 + d.tension)
 
 	// Increase chance if player hasn't been scared for a while
 	if len(d.scareHistory) > 0 {
 		lastScare := d.scareHistory[len(d.scareHistory)-1]
-		timeSinceLast := time.Since(lastScare.Timestamp)
-		if timeSinceLast > 30*time.Second {
-			baseChance += 0.1
+		timeSinceLast := common.Since(lastScare.Timestamp)
+		if timeSinceLast < settings.MinScareInterval || !d.scareTimingReady(timeSinceLast) {
+			return false
 		}
-		if timeSinceLast > 60*time.Second {
-			baseChance += 0.2
+
+		// Relief is never hurried
+		if d.phase != PhaseRelief {
+			if timeSinceLast > 30*time.Second {
+				baseChance += 0.1
+			}
+			if timeSinceLast > 60*time.Second {
+				baseChance += 0.2
+			}
 		}
 	} else {
 		// If there hasn't been a scare yet, increase the chance
 		baseChance += 0.3
 	}
 
-	// Add randomness
-	return rand.Float64() < baseChance
+	// Add randomness, trusting high-priority recommendations more
+	return common.Rand().Float64() < d.recommendedChance(baseChance)
 }
 
-// createScareEvent creates a scare event based on player behavior
-func (d *Director) createScareEvent() common.ScareEvent {
-	// Choose event type based on effectiveness
-	eventType := d.chooseEventType()
+// createScareEvent creates a scare event based on player behavior, together with
+// the situation it is chosen for, which the bandit learns under
+func (d *Director) createScareEvent() (common.ScareEvent, BanditContext) {
+	// Taken before the scare is recorded, so that the wait since the last scare still counts
+	context := d.banditContext()
 
-	// Determine intensity based on mood and player analysis
-	intensity := d.mood * (0.7 + rand.Float64()*0.3)
+	// Determine intensity based on mood, pacing phase and player analysis
+	intensity := (d.mood + d.phaseSettings().Intensity) / 2 * (0.7 + common.Rand().Float64()*0.3)
 
 	// If the player reacts weakly to scares, increase intensity
 	if d.playerBehavior.ReactivityToScares < 0.3 {
 		intensity *= 1.5
 	}
+	intensity *= d.patternIntensityScale()
 
 	// Limit intensity
 	if intensity > 1.0 {
 		intensity = 1.0
 	}
 
+	// Choose the event type that works best at this intensity in the current situation
+	chosen := d.chooseEventType(context, intensity)
+	eventType := d.recommendedEventType(chosen)
+
+	// Place the scare where the observer and the player's patterns suggest
+	anchor := d.scareAnchor()
+
 	// Create the event
 	event := common.ScareEvent{
 		Type:      eventType,
 		Intensity: intensity,
-		Position:  common.VectorFromEntity(d.player.Position), // By default near the player
-		Duration:  time.Duration(2+rand.Intn(5)) * time.Second,
-		Timestamp: time.Now(), // Add timestamp to fix the missing field error
+		Position:  anchor.ToCommonVector(),
+		Duration:  time.Duration(2+common.Rand().Intn(5)) * time.Second,
+		Timestamp: common.Now(), // Add timestamp to fix the missing field error
 	}
 
+	// Sensory scares are placed around the anchor, relative to where the player is facing
+	d.placeScare(&event, anchor)
+
 	// For some event types, additional configuration is needed
 	if eventType == common.EventCreatureAppearance {
 		// Choose creature type
 		event.CreatureType = d.chooseCreatureType()
 
-		// Set creature spawn position
-		angle := rand.Float64() * 2 * math.Pi
-		distance := 10.0 + rand.Float64()*20.0
-		event.Position = common.Vector2D{
-			X: d.player.Position.X + math.Cos(angle)*distance,
-			Y: d.player.Position.Y + math.Sin(angle)*distance,
+		// Ambush the player on their routes, unless this is a reveal meant to be seen
+		reveal := d.phase == PhasePeak && common.Rand().Float64() < revealChance
+		if spot, ok := d.placeAmbush(reveal, anchor); ok {
+			event.Position = spot.position
+			d.explainPlacement(spot, reveal)
+		} else {
+			d.placeRandomly(&event, anchor)
 		}
-	}
 
-	return event
-}
+		// A doppelganger replays one of the player's own routes instead
+		if event.CreatureType == "doppelganger" {
+			event.Path = d.buildMimicRoute()
+			if len(event.Path) > 0 {
+				event.Position = event.Path[0]
+			}
+		}
+	}
 
-// chooseEventType chooses an event type based on effectiveness
-func (d *Director) chooseEventType() common.ScareEventType {
-	// If we don't have data on effectiveness, choose a random type
-	if len(d.scareEffectiveness) == 0 {
-		return common.ScareEventType(rand.Intn(6))
+	// Environment changes block the way ahead
+	if eventType == common.EventEnvironmentChange {
+		if spot, ok := d.placeAmbush(false, anchor); ok {
+			event.Position = spot.position
+			d.explainPlacement(spot, false)
+		}
 	}
 
-	// Choose more effective types with higher probability
-	// ...
+	// Some personalities cry wolf
+	d.raiseFalseAlarm(&event)
+
+	d.explainScare(event, chosen)
 
-	// Simplified version - random choice
-	return common.ScareEventType(rand.Intn(6))
+	return event, context
+}
+
+// placeRandomly puts a creature at a random point around the anchor, preferring points outside the player's view
+func (d *Director) placeRandomly(event *common.ScareEvent, anchor entity.Vector2D) {
+	view := d.player.ViewFrustum()
+	for attempt := 0; attempt < 8; attempt++ {
+		angle := common.Rand().Float64() * 2 * math.Pi
+		distance := 10.0 + common.Rand().Float64()*20.0
+		event.Position = common.Vector2D{
+			X: anchor.X + math.Cos(angle)*distance,
+			Y: anchor.Y + math.Sin(angle)*distance,
+		}
+
+		if !view.Contains(entity.FromCommonVector(event.Position)) {
+			return
+		}
+	}
 }
 
 // chooseCreatureType chooses a creature type
@@ -204,33 +313,52 @@ func (d *Director) chooseCreatureType() string {
 		"shadow", "spider", "phantom", "doppelganger", "wendigo", "faceless",
 	}
 
-	return creatureTypes[rand.Intn(len(creatureTypes))]
+	// The peak brings out the most dangerous creatures
+	if d.phase == PhasePeak {
+		creatureTypes = []string{"wendigo", "faceless", "doppelganger"}
+	}
+
+	// The personality has its favorites
+	if creatureType, ok := d.choosePersonalityCreature(creatureTypes); ok {
+		return creatureType
+	}
+
+	return creatureTypes[common.Rand().Intn(len(creatureTypes))]
 }
 
-// executeScareEvent executes a scare event
-func (d *Director) executeScareEvent(event common.ScareEvent) {
-	// Add the event to history
-	d.scareHistory = append(d.scareHistory, event)
+// executeScareEvent executes a scare event chosen in the given context
+func (d *Director) executeScareEvent(event common.ScareEvent, context BanditContext) {
+	// Creatures that could hurt the player must play fair
+	d.enforceFairness(&event)
 
 	// Perform actions depending on the event type
 	switch event.Type {
 	case common.EventAmbientSound:
-		// Play sound
-		// ...
+		// Play a distant sound
+		if presenter, ok := d.presenter.(interface {
+			PlayAmbientScare(common.ScareEvent)
+		}); ok {
+			presenter.PlayAmbientScare(event)
+		}
 
 	case common.EventSuddenNoise:
 		// Sudden loud noise
-		// ...
+		if presenter, ok := d.presenter.(interface {
+			PlaySuddenNoise(common.ScareEvent)
+		}); ok {
+			presenter.PlaySuddenNoise(event)
+		}
 
 	case common.EventCreatureAppearance:
-		// Create creature - this requires interacting with the world interface
-		// We'll add a type check to ensure we're using the world correctly
-		if worldObj, ok := d.world.(interface {
-			SpawnCreature(string, common.Vector2D) interface{}
-		}); ok {
-			worldObj.SpawnCreature(event.CreatureType, event.Position)
+		// A rejected spawn scares nobody, so it is neither recorded nor measured
+		if !d.spawnCreature(event) {
+			d.logDecision("%s at (%.0f, %.0f) could not appear", event.CreatureType, event.Position.X, event.Position.Y)
+			return
 		}
 
+		// Only a creature that really appeared holds back the next one while health is critical
+		d.lastDamageScare = common.Now()
+
 	case common.EventEnvironmentChange:
 		// Change environment
 		if worldObj, ok := d.world.(interface {
@@ -241,36 +369,68 @@ func (d *Director) executeScareEvent(event common.ScareEvent) {
 
 	case common.EventHallucination:
 		// Create hallucination
-		// ...
+		if presenter, ok := d.presenter.(interface {
+			ShowHallucination(common.ScareEvent)
+		}); ok {
+			presenter.ShowHallucination(event)
+		}
 
 	case common.EventWhisper:
 		// Whisper
-		// ...
+		if presenter, ok := d.presenter.(interface {
+			PlayWhisper(common.ScareEvent)
+		}); ok {
+			presenter.PlayWhisper(event)
+		}
 	}
 
+	// Let the detector recognize a freeze after the scare
+	if d.detector != nil {
+		d.detector.NoteScare(event.Timestamp)
+	}
+
+	// Add the event to history
+	d.scareHistory = append(d.scareHistory, event)
+
 	// Reduce player's sanity based on event intensity
 	d.player.ReduceSanity(event.Intensity * 5)
-}
 
-// analyzeScareEffectiveness analyzes the effectiveness of past attempts to scare
-func (d *Director) analyzeScareEffectiveness() {
-	// This method will analyze how much the sanity level changed
-	// after each scare event
-	// ...
+	// Watch how the player reacts to learn which scares work
+	d.beginMeasurement(event, context)
+	d.reportScare(event)
 }
 
-// updateMoodAndTension updates the director's mood and tension level
-func (d *Director) updateMoodAndTension() {
-	// Mood depends on how effectively we're scaring the player
-	// ...
+// spawnCreature brings out the creature of a creature appearance and reports whether one appeared
+func (d *Director) spawnCreature(event common.ScareEvent) bool {
+	// A personality with a stalker sends it after the player instead of a new creature
+	if d.huntWithStalker() {
+		return true
+	}
+
+	// A doppelganger with a route is spawned as a mimic of the player
+	if len(event.Path) > 0 {
+		if worldObj, ok := d.world.(interface {
+			SpawnMimic([]common.Vector2D) bool
+		}); ok {
+			return worldObj.SpawnMimic(event.Path)
+		}
+	}
 
-	// Tension increases over time and drops after a successful scare
-	d.tension += 0.01
+	// Sometimes breed a variant of the creatures that scared the player the most
+	if event.CreatureType != "doppelganger" && d.spawnBredCreature(event) {
+		return true
+	}
 
-	// Limit tension
-	if d.tension > 1.0 {
-		d.tension = 1.0
+	// Spawns go through the world's population manager, which checks
+	// budgets, safe zones and spawn tiles and may reject the request
+	worldObj, ok := d.world.(interface {
+		RequestSpawn(string, common.Vector2D) bool
+	})
+	if !ok || !worldObj.RequestSpawn(event.CreatureType, event.Position) {
+		return false
 	}
+	d.adoptStalker(event)
+	return true
 }
 
 // modifyEnvironment modifies the surrounding world
@@ -281,6 +441,6 @@ func (d *Director) modifyEnvironment() {
 
 // manageCreatures manages creatures in the world
 func (d *Director) manageCreatures() {
-	// Logic for managing creatures will go here
-	// ...
+	// A stalker does not give up the hunt
+	d.keepHunting()
 }
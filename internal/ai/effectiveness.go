@@ -0,0 +1,201 @@
+package ai
+
+import (
+	"math"
+	"time"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+// scareEventTypeCount is the number of scare event types the director can choose from
+const scareEventTypeCount = int(common.EventWhisper) + 1
+
+const (
+	scareMeasureWindow  = 5 * time.Second // How long the player's reaction is observed after a scare
+	scareBaselineWindow = 5 * time.Second // Behavior before the scare that the reaction is compared to
+	scareLearningRate   = 0.3             // How fast new measurements replace the old effectiveness
+	scareSanityScale    = 10.0            // Sanity loss that counts as a full reaction
+	scareFreezeRatio    = 0.3             // Speed below this fraction of the baseline counts as freezing
+	scareSpeedUpRatio   = 1.5             // Speed above this multiple of the baseline counts as fleeing
+)
+
+// Weights of the reaction components in the effectiveness score
+const (
+	sanityWeight   = 0.4
+	movementWeight = 0.35
+	pauseWeight    = 0.25
+)
+
+// scareMeasurement is a scare waiting for the player's reaction to be measured
+type scareMeasurement struct {
+	event        common.ScareEvent
+	context      BanditContext // Situation the scare was played in
+	sanityBefore float64
+}
+
+// beginMeasurement starts observing the player's reaction to a scare played in the context
+func (d *Director) beginMeasurement(event common.ScareEvent, context BanditContext) {
+	d.pendingScares = append(d.pendingScares, scareMeasurement{
+		event:        event,
+		context:      context,
+		sanityBefore: d.player.Sanity,
+	})
+}
+
+// analyzeScareEffectiveness scores the scares whose measurement window has passed
+func (d *Director) analyzeScareEffectiveness() {
+	pending := d.pendingScares[:0]
+	for _, measurement := range d.pendingScares {
+		if common.Since(measurement.event.Timestamp) < scareMeasureWindow {
+			pending = append(pending, measurement)
+			continue
+		}
+
+		score := d.scoreScare(measurement)
+		d.recordEffectiveness(measurement.event.Type, score)
+		d.bandit.Update(measurement.context, ScareArm{Type: measurement.event.Type, Intensity: intensityBucket(measurement.event.Intensity)}, score)
+		d.reportScareOutcome(measurement.event, score)
+	}
+	d.pendingScares = pending
+}
+
+// scoreScare rates the player's reaction to a scare from 0 (ignored) to 1 (strong reaction)
+func (d *Director) scoreScare(measurement scareMeasurement) float64 {
+	start := measurement.event.Timestamp
+	before := d.actionsBetween(start.Add(-scareBaselineWindow), start)
+	after := d.actionsBetween(start, start.Add(scareMeasureWindow))
+
+	// Sanity lost while the scare was playing out
+	sanity := clamp01((measurement.sanityBefore - d.player.Sanity) / scareSanityScale)
+
+	// Micro-behaviors such as freezing or sprinting away are a reaction too
+	movement := math.Max(movementReaction(before, after), d.signalReaction(start, start.Add(scareMeasureWindow)))
+
+	return sanity*sanityWeight +
+		movement*movementWeight +
+		pauseReaction(before, after, start)*pauseWeight
+}
+
+// recordEffectiveness blends a new score into the effectiveness of the event type
+func (d *Director) recordEffectiveness(eventType common.ScareEventType, score float64) {
+	if previous, ok := d.scareEffectiveness[eventType]; ok {
+		d.scareEffectiveness[eventType] = previous*(1-scareLearningRate) + score*scareLearningRate
+	} else {
+		d.scareEffectiveness[eventType] = score
+	}
+
+	// The player's overall reactivity follows every measured scare
+	d.playerBehavior.ReactivityToScares = d.playerBehavior.ReactivityToScares*(1-scareLearningRate) + score*scareLearningRate
+
+	// Effective scares stress the player and drive the pacing
+	d.addScareStress(score)
+}
+
+// ScareEffectiveness returns the measured effectiveness of an event type and whether it was measured
+func (d *Director) ScareEffectiveness(eventType common.ScareEventType) (float64, bool) {
+	value, ok := d.scareEffectiveness[eventType]
+	return value, ok
+}
+
+// chooseEventType asks the bandit for the event type that should work best at this intensity
+// in the player's situation. The peak exploits what is known instead of exploring.
+func (d *Director) chooseEventType(context BanditContext, intensity float64) common.ScareEventType {
+	eventType, _ := d.bandit.ChooseWeighted(context, intensityBucket(intensity), d.phase != PhasePeak, d.personality.eventWeight)
+	return eventType
+}
+
+// actionsBetween returns the player's actions recorded in the time range
+func (d *Director) actionsBetween(from, to time.Time) []entity.PlayerActionRecord {
+	actions := []entity.PlayerActionRecord{}
+	for _, record := range d.player.ActionLog {
+		if record.Timestamp.Before(from) || record.Timestamp.After(to) {
+			continue
+		}
+		actions = append(actions, record)
+	}
+	return actions
+}
+
+// movementReaction measures how much the player's movement changed after a scare.
+// Both freezing and fleeing count as a reaction.
+func movementReaction(before, after []entity.PlayerActionRecord) float64 {
+	// Starting to run or hide is a clear flight response
+	if countActions(after, entity.ActionRun, entity.ActionHide) > countActions(before, entity.ActionRun, entity.ActionHide) {
+		return 1
+	}
+
+	speedBefore := movementSpeed(before, scareBaselineWindow)
+	speedAfter := movementSpeed(after, scareMeasureWindow)
+
+	if speedBefore <= 0 {
+		// The player was standing still; any sudden movement is a reaction
+		return clamp01(speedAfter / entity.MoveSpeed)
+	}
+
+	ratio := speedAfter / speedBefore
+	switch {
+	case ratio < scareFreezeRatio:
+		return 1 - ratio/scareFreezeRatio*0.5
+	case ratio > scareSpeedUpRatio:
+		return clamp01(0.5 + (ratio-scareSpeedUpRatio)*0.5)
+	default:
+		return math.Abs(ratio-1) * 0.5
+	}
+}
+
+// pauseReaction measures how long the player stopped interacting after a scare
+func pauseReaction(before, after []entity.PlayerActionRecord, start time.Time) float64 {
+	// The player was interacting and stopped completely
+	if countActions(before, entity.ActionInteract) > 0 && countActions(after, entity.ActionInteract) == 0 {
+		return 1
+	}
+
+	// Otherwise measure the longest gap between any actions
+	longest := time.Duration(0)
+	last := start
+	for _, record := range after {
+		longest = max(longest, record.Timestamp.Sub(last))
+		last = record.Timestamp
+	}
+	longest = max(longest, start.Add(scareMeasureWindow).Sub(last))
+
+	return clamp01(longest.Seconds() / scareMeasureWindow.Seconds())
+}
+
+// movementSpeed returns the distance the player covered per second
+func movementSpeed(actions []entity.PlayerActionRecord, window time.Duration) float64 {
+	distance := 0.0
+	var last *entity.PlayerActionRecord
+	for i := range actions {
+		if actions[i].Action != entity.ActionMove {
+			continue
+		}
+		if last != nil {
+			dx := actions[i].Position.X - last.Position.X
+			dy := actions[i].Position.Y - last.Position.Y
+			distance += math.Sqrt(dx*dx + dy*dy)
+		}
+		last = &actions[i]
+	}
+	return distance / window.Seconds()
+}
+
+// countActions counts actions of the given types
+func countActions(actions []entity.PlayerActionRecord, types ...entity.PlayerAction) int {
+	count := 0
+	for _, record := range actions {
+		for _, action := range types {
+			if record.Action == action {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// clamp01 limits a value to the range from 0 to 1
+func clamp01(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
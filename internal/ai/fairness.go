@@ -0,0 +1,163 @@
+package ai
+
+import (
+	"math"
+	"time"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+// Fairness rules, as named in the decision log and in AvoidedViolations
+const (
+	RuleSpawnDistance  = "spawn_distance"  // A creature would appear too close to the player
+	RuleCriticalHealth = "critical_health" // Another damage scare while health is critical
+	RuleEscapeRoute    = "escape_route"    // A creature would cut the player off from every safe zone
+	RuleSafeZone       = "safe_zone"       // A creature would appear in a safe zone
+	RuleGrace          = "grace"           // A creature would appear right after loading or respawning
+)
+
+// FairnessRules are the guarantees the director never breaks, however scary it wants to be.
+// A zero value switches the corresponding rule off.
+type FairnessRules struct {
+	MinSpawnDistance    float64       // Creatures never appear closer to the player than this
+	CriticalHealth      float64       // Health below which damage scares are spaced out
+	DamageScareCooldown time.Duration // Minimum time between damage scares while health is critical
+	EscapeRoute         bool          // The zone graph must keep a way to a safe zone open
+	SafeZones           bool          // No creature appears in a safe zone
+	Grace               time.Duration // No creature appears for this long after loading or respawning
+}
+
+// DefaultFairnessRules returns the rules the game is played with
+func DefaultFairnessRules() FairnessRules {
+	return FairnessRules{
+		MinSpawnDistance:    8,
+		CriticalHealth:      entity.MaxHealth * 0.3,
+		DamageScareCooldown: 60 * time.Second,
+		EscapeRoute:         true,
+		SafeZones:           true,
+		Grace:               30 * time.Second,
+	}
+}
+
+// SetFairnessRules replaces the fairness rules and passes the spawn rules on to the world
+func (d *Director) SetFairnessRules(rules FairnessRules) {
+	d.fairness = rules
+	d.applySpawnRules()
+}
+
+// FairnessRules returns the current fairness rules
+func (d *Director) FairnessRules() FairnessRules {
+	return d.fairness
+}
+
+// StartGrace gives the player a grace period without creatures. Call it after loading or respawning.
+func (d *Director) StartGrace() {
+	d.graceStart = common.Now()
+	d.applySpawnRules()
+}
+
+// InGrace checks whether the grace period is still running
+func (d *Director) InGrace() bool {
+	return common.Since(d.graceStart) < d.fairness.Grace
+}
+
+// AvoidedViolations returns how many times each fairness rule stopped a scare
+func (d *Director) AvoidedViolations() map[string]int {
+	avoided := make(map[string]int, len(d.avoided))
+	for rule, count := range d.avoided {
+		avoided[rule] = count
+	}
+	return avoided
+}
+
+// applySpawnRules tells the world which spawns to refuse, so that creatures the director
+// does not control directly follow the same rules
+func (d *Director) applySpawnRules() {
+	if worldObj, ok := d.world.(interface {
+		SetSpawnRules(minDistance float64, safeZones, escapeRoute bool, graceUntil time.Time)
+	}); ok {
+		rules := d.fairness
+		worldObj.SetSpawnRules(rules.MinSpawnDistance, rules.SafeZones, rules.EscapeRoute, d.graceStart.Add(rules.Grace))
+	}
+}
+
+// enforceFairness checks a scare that could hurt the player against the fairness rules.
+// A spawn that is merely too close is moved away; any other violation turns the creature
+// into a hallucination of itself, which frightens without hurting.
+func (d *Director) enforceFairness(event *common.ScareEvent) {
+	if event.Type != common.EventCreatureAppearance {
+		return
+	}
+
+	rule := d.fairnessViolation(event)
+	if rule == "" {
+		return
+	}
+
+	d.avoided[rule]++
+	d.logDecision("fairness: avoided %s, %s at (%.0f, %.0f) shown as a hallucination",
+		rule, event.CreatureType, event.Position.X, event.Position.Y)
+
+	event.Type = common.EventHallucination
+	event.Path = nil
+}
+
+// fairnessViolation returns the first rule the scare would break, or an empty string
+func (d *Director) fairnessViolation(event *common.ScareEvent) string {
+	rules := d.fairness
+
+	if d.InGrace() {
+		return RuleGrace
+	}
+
+	if rules.CriticalHealth > 0 && d.player.Health < rules.CriticalHealth &&
+		!d.lastDamageScare.IsZero() && common.Since(d.lastDamageScare) < rules.DamageScareCooldown {
+		return RuleCriticalHealth
+	}
+
+	if rule := d.keepDistance(event); rule != "" {
+		return rule
+	}
+
+	if rules.SafeZones && d.zoneNameAt(event.Position) == "safe" {
+		return RuleSafeZone
+	}
+
+	if rules.EscapeRoute {
+		if worldObj, ok := d.world.(interface {
+			HasEscapeRoute(common.Vector2D) bool
+		}); ok && !worldObj.HasEscapeRoute(event.Position) {
+			return RuleEscapeRoute
+		}
+	}
+
+	return ""
+}
+
+// keepDistance pushes a spawn that is too close out to the minimum distance.
+// A mimic must start where its route starts, so it cannot be moved.
+func (d *Director) keepDistance(event *common.ScareEvent) string {
+	minDistance := d.fairness.MinSpawnDistance
+	origin := d.player.Position.ToCommonVector()
+
+	dist := common.Distance(event.Position, origin)
+	if dist >= minDistance {
+		return ""
+	}
+	if len(event.Path) > 0 {
+		return RuleSpawnDistance
+	}
+
+	// Straight behind the player if the spawn was right on top of them
+	dirX, dirY := -math.Cos(d.player.Direction), -math.Sin(d.player.Direction)
+	if dist > 0 {
+		dirX, dirY = (event.Position.X-origin.X)/dist, (event.Position.Y-origin.Y)/dist
+	}
+	event.Position = common.Vector2D{X: origin.X + dirX*minDistance, Y: origin.Y + dirY*minDistance}
+
+	d.avoided[RuleSpawnDistance]++
+	d.logDecision("fairness: avoided %s, %s moved from %.1f to %.0f tiles away",
+		RuleSpawnDistance, event.CreatureType, dist, minDistance)
+	return ""
+}
@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+const (
+	mimicRouteLength      = 24   // Number of waypoints in a replayed route segment
+	mimicWaypointSpacing  = 1.0  // Minimum distance between replayed waypoints
+	mimicMinSpawnDistance = 15.0 // The mimic must start out of the player's reach
+)
+
+// buildMimicRoute picks a segment of the player's own movement log for a doppelganger
+// to replay. Segments on routes the player repeats the most (according to the analyzer)
+// are preferred, so the mimic shows up where the player feels safe.
+func (d *Director) buildMimicRoute() []common.Vector2D {
+	path := d.recordedPath()
+	if len(path) < mimicRouteLength {
+		return nil
+	}
+
+	bestScore := -1.0
+	var best []entity.Vector2D
+
+	for start := 0; start+mimicRouteLength <= len(path); start += mimicRouteLength / 2 {
+		segment := path[start : start+mimicRouteLength]
+
+		// The mimic must appear somewhere else, not next to the player
+		if distance(segment[0], d.player.Position) < mimicMinSpawnDistance {
+			continue
+		}
+
+		score := d.routeTrust(segment) + common.Rand().Float64()*0.1
+		if score > bestScore {
+			bestScore = score
+			best = segment
+		}
+	}
+
+	route := make([]common.Vector2D, 0, len(best))
+	for _, point := range best {
+		route = append(route, point.ToCommonVector())
+	}
+
+	return route
+}
+
+// recordedPath returns the player's movement log thinned to evenly spaced waypoints
+func (d *Director) recordedPath() []entity.Vector2D {
+	path := []entity.Vector2D{}
+
+	for _, record := range d.player.ActionLog {
+		if record.Action != entity.ActionMove {
+			continue
+		}
+
+		if len(path) > 0 && distance(path[len(path)-1], record.Position) < mimicWaypointSpacing {
+			continue
+		}
+
+		path = append(path, record.Position)
+	}
+
+	return path
+}
+
+// routeTrust returns the average familiarity of a route segment
+func (d *Director) routeTrust(segment []entity.Vector2D) float64 {
+	if d.analyzer == nil || len(segment) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, point := range segment {
+		total += d.analyzer.RouteTrust(point)
+	}
+
+	return total / float64(len(segment))
+}
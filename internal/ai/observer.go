@@ -26,6 +26,30 @@ const (
 	FearUnknown
 )
 
+// String возвращает название типа страха
+func (f FearType) String() string {
+	switch f {
+	case FearDarkness:
+		return "darkness"
+	case FearCreatures:
+		return "creatures"
+	case FearSuddenNoises:
+		return "sudden noises"
+	case FearIsolation:
+		return "isolation"
+	case FearChasing:
+		return "chasing"
+	case FearGore:
+		return "gore"
+	case FearClaustrophobia:
+		return "claustrophobia"
+	case FearOpenSpaces:
+		return "open spaces"
+	default:
+		return "unknown"
+	}
+}
+
 // ReactorType представляет тип реакции игрока
 type ReactorType int
 
@@ -38,6 +62,26 @@ const (
 	ReactorHesitant                      // Нерешительный игрок
 )
 
+// String возвращает название типа реакции
+func (r ReactorType) String() string {
+	switch r {
+	case ReactorCautious:
+		return "cautious"
+	case ReactorBold:
+		return "bold"
+	case ReactorPanic:
+		return "panic"
+	case ReactorMethodical:
+		return "methodical"
+	case ReactorReckless:
+		return "reckless"
+	case ReactorHesitant:
+		return "hesitant"
+	default:
+		return "unknown"
+	}
+}
+
 // ActionType представляет тип действия игрока
 type ActionType int
 
@@ -93,17 +137,24 @@ type ObserverSystem struct {
 	random       *util.RandomGenerator
 
 	playerActions  []PlayerAction
+	lastLogged     time.Time // Время последней записи журнала игрока, уже перенесенной в историю
 	fearResponses  map[FearType][]FearResponse
 	reactorProfile map[ReactorType]float64
 	fearProfile    map[FearType]float64
 
 	lastObservationTime time.Time
 	observationInterval time.Duration
+	lastContextUpdate   time.Time
 
 	context ObservationContext
 
 	predictedActions  map[ActionType]float64
 	recommendedScares []ScareRecommendation
+
+	heart          *HeartMonitor   // Пульс игрока, если подключен датчик
+	heartResponses []heartResponse // Реакции на страх, ждущие ответа сердца
+
+	exposure map[FearType]*situationExposure // Время в местах, которых игрок может бояться, и реакции в них
 }
 
 // ScareRecommendation представляет рекомендацию для испуга
@@ -123,15 +174,16 @@ func NewObserverSystem(player *entity.Player, eventManager *event.EventManager,
 		eventManager: eventManager,
 		analyzer:     analyzer,
 		director:     director,
-		random:       util.NewRandomGenerator(time.Now().UnixNano()),
+		random:       util.NewRandomGenerator(common.Rand().Int63()),
 
 		playerActions:  []PlayerAction{},
 		fearResponses:  make(map[FearType][]FearResponse),
 		reactorProfile: make(map[ReactorType]float64),
 		fearProfile:    make(map[FearType]float64),
 
-		lastObservationTime: time.Now(),
+		lastObservationTime: common.Now(),
 		observationInterval: 5 * time.Second, // Обновлять анализ каждые 5 секунд
+		lastContextUpdate:   common.Now(),
 
 		context: ObservationContext{
 			LightLevel:         0.5,
@@ -144,6 +196,8 @@ func NewObserverSystem(player *entity.Player, eventManager *event.EventManager,
 
 		predictedActions:  make(map[ActionType]float64),
 		recommendedScares: []ScareRecommendation{},
+
+		exposure: make(map[FearType]*situationExposure),
 	}
 }
 
@@ -196,7 +250,10 @@ func (o *ObserverSystem) subscribeToEvents() {
 
 // Update обновляет состояние системы наблюдения
 func (o *ObserverSystem) Update() {
-	currentTime := time.Now()
+	currentTime := common.Now()
+
+	// Удары и взаимодействия не приходят событиями, их видно только в журнале игрока
+	o.recordPlayerLog()
 
 	// Проверяем, прошел ли достаточный интервал для анализа
 	if currentTime.Sub(o.lastObservationTime) >= o.observationInterval {
@@ -207,6 +264,9 @@ func (o *ObserverSystem) Update() {
 
 	// Обновляем контекст
 	o.updateContext()
+
+	// Дописываем реакции, на которые сердце уже успело ответить
+	o.updateHeartResponses()
 }
 
 // updateContext обновляет контекст наблюдения
@@ -221,8 +281,15 @@ func (o *ObserverSystem) updateContext() {
 		}
 	}
 
-	// Увеличиваем время с последнего испуга
-	o.context.TimeSinceLastScare += time.Second
+	// Осматриваем окружение игрока: свет, простор, тропы и существ рядом
+	o.observeWorld()
+
+	// Увеличиваем время с последнего испуга и время, проведенное в разных местах
+	now := common.Now()
+	elapsed := now.Sub(o.lastContextUpdate)
+	o.context.TimeSinceLastScare += elapsed
+	o.trackExposure(elapsed)
+	o.lastContextUpdate = now
 }
 
 // recordMovement записывает движение игрока
@@ -270,6 +337,44 @@ func (o *ObserverSystem) recordMovement(data event.EventData) {
 	})
 }
 
+// recordPlayerLog переносит в историю удары и взаимодействия, записанные в журнал игрока
+// после прошлого вызова. Без них смелого и безрассудного игрока не отличить от паникующего.
+func (o *ObserverSystem) recordPlayerLog() {
+	if o.player == nil {
+		return
+	}
+
+	// Журнал упорядочен по времени: ищем первую новую запись с конца
+	log := o.player.ActionLog
+	first := len(log)
+	for first > 0 && log[first-1].Timestamp.After(o.lastLogged) {
+		first--
+	}
+
+	for _, record := range log[first:] {
+		actionType := ActionMove
+		switch record.Action {
+		case entity.ActionAttack:
+			actionType = ActionAttack
+		case entity.ActionInteract:
+			actionType = ActionInteract
+		default:
+			continue
+		}
+
+		o.addPlayerAction(PlayerAction{
+			Type:      actionType,
+			Position:  record.Position,
+			Timestamp: record.Timestamp,
+			Context:   make(map[string]interface{}),
+		})
+	}
+
+	if len(log) > 0 {
+		o.lastLogged = log[len(log)-1].Timestamp
+	}
+}
+
 // recordDamage записывает получение урона
 func (o *ObserverSystem) recordDamage(data event.EventData) {
 	// Проверяем, что данные содержат источник урона
@@ -363,12 +468,15 @@ func (o *ObserverSystem) recordScareResponse(data event.EventData) {
 		}
 	}
 
-	// Записываем реакцию на страх
-	o.recordFearResponse(FearResponse{
+	// Записываем реакцию на страх; с датчиком пульса - после того, как ответит сердце
+	response := FearResponse{
 		FearType:       fearType,
 		StrengthOfFear: intensity,
 		SanityLoss:     o.context.RecentSanityLoss,
-	})
+	}
+	if !o.watchHeart(response) {
+		o.recordFearResponse(response)
+	}
 
 	// Сбрасываем время с последнего испуга
 	o.context.TimeSinceLastScare = 0
@@ -508,6 +616,9 @@ func (o *ObserverSystem) analyzeReactorProfile() {
 
 // analyzeFearProfile анализирует профиль страхов игрока
 func (o *ObserverSystem) analyzeFearProfile() {
+	// Страхи мест измеряются по тому, как игрок ведет себя в таких местах
+	o.measureSituationalFears()
+
 	// Если нет данных о реакциях на страх, выходим
 	if len(o.fearResponses) == 0 {
 		return
@@ -591,7 +702,7 @@ func (o *ObserverSystem) getDominantReactorType() ReactorType {
 	dominantType := ReactorCautious
 
 	for reactorType, value := range o.reactorProfile {
-		if value > maxValue {
+		if value > maxValue || (value == maxValue && reactorType < dominantType) {
 			maxValue = value
 			dominantType = reactorType
 		}
@@ -637,7 +748,10 @@ func (o *ObserverSystem) getEffectiveFearTypes() []FearType {
 	}
 
 	sort.Slice(fearTypes, func(i, j int) bool {
-		return effectiveness[fearTypes[i]] > effectiveness[fearTypes[j]]
+		if effectiveness[fearTypes[i]] != effectiveness[fearTypes[j]] {
+			return effectiveness[fearTypes[i]] > effectiveness[fearTypes[j]]
+		}
+		return fearTypes[i] < fearTypes[j]
 	})
 
 	// Возвращаем до 3 наиболее эффективных типов страха
@@ -725,6 +839,30 @@ func (o *ObserverSystem) GetScareRecommendation() *ScareRecommendation {
 	return &o.recommendedScares[0]
 }
 
+// GetScareRecommendations возвращает до count лучших рекомендаций для испуга
+func (o *ObserverSystem) GetScareRecommendations(count int) []ScareRecommendation {
+	count = min(count, len(o.recommendedScares))
+	return append([]ScareRecommendation(nil), o.recommendedScares[:count]...)
+}
+
+// LoadProfiles загружает профили страхов и реакций, сохраненные в прошлых сессиях
+func (o *ObserverSystem) LoadProfiles(fearProfile map[FearType]float64, reactorProfile map[ReactorType]float64) {
+	for fearType, value := range fearProfile {
+		o.fearProfile[fearType] = value
+	}
+	for reactorType, value := range reactorProfile {
+		o.reactorProfile[reactorType] = value
+	}
+
+	// Рекомендации сразу учитывают то, что пугало игрока раньше
+	o.GenerateScareRecommendations()
+}
+
+// GetContext возвращает текущий контекст наблюдения
+func (o *ObserverSystem) GetContext() ObservationContext {
+	return o.context
+}
+
 // GetPlayerFearProfile возвращает профиль страхов игрока
 func (o *ObserverSystem) GetPlayerFearProfile() map[FearType]float64 {
 	return o.fearProfile
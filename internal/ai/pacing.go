@@ -0,0 +1,240 @@
+package ai
+
+import (
+	"math"
+	"time"
+
+	"nightmare/internal/common"
+)
+
+// PacingPhase is a phase of the director's pacing cycle
+type PacingPhase int
+
+const (
+	PhaseBuildUp PacingPhase = iota // Tension slowly rises, scares are rare and mild
+	PhasePeak                       // The worst events are brought out
+	PhaseSustain                    // Tension is held high without escalating further
+	PhaseRelief                     // The player is given time to recover
+)
+
+// String returns the name of the phase
+func (p PacingPhase) String() string {
+	switch p {
+	case PhaseBuildUp:
+		return "build-up"
+	case PhasePeak:
+		return "peak"
+	case PhaseSustain:
+		return "sustain"
+	case PhaseRelief:
+		return "relief"
+	default:
+		return "unknown"
+	}
+}
+
+// Difficulty selects the pacing curve
+type Difficulty int
+
+const (
+	DifficultyEasy Difficulty = iota
+	DifficultyNormal
+	DifficultyHard
+	DifficultyNightmare
+)
+
+// PhaseSettings controls how the director behaves in one pacing phase
+type PhaseSettings struct {
+	Duration         time.Duration // How long the phase lasts if stress does not end it earlier
+	ScareChance      float64       // Chance of a scare per director update
+	MinScareInterval time.Duration // Minimum time between scares
+	Intensity        float64       // Scare intensity multiplier
+	Tension          float64       // Tension the director moves towards
+	Mood             float64       // Mood the director moves towards
+}
+
+// PacingCurve describes the whole pacing cycle for a difficulty
+type PacingCurve struct {
+	Phases        map[PacingPhase]PhaseSettings
+	PeakStress    float64       // Stress that ends the build-up early
+	MaxStress     float64       // Stress that cuts the peak or sustain short
+	RecoverStress float64       // Stress below which relief may end
+	QuietDuration time.Duration // Guaranteed calm at the start of relief
+}
+
+// PacingCurveFor returns the pacing curve of a difficulty
+func PacingCurveFor(difficulty Difficulty) PacingCurve {
+	curve := PacingCurve{
+		Phases: map[PacingPhase]PhaseSettings{
+			PhaseBuildUp: {Duration: 90 * time.Second, ScareChance: 0.03, MinScareInterval: 20 * time.Second, Intensity: 0.5, Tension: 0.6, Mood: 0.3},
+			PhasePeak:    {Duration: 30 * time.Second, ScareChance: 0.25, MinScareInterval: 4 * time.Second, Intensity: 1.0, Tension: 1.0, Mood: 0.9},
+			PhaseSustain: {Duration: 45 * time.Second, ScareChance: 0.08, MinScareInterval: 10 * time.Second, Intensity: 0.7, Tension: 0.7, Mood: 0.6},
+			PhaseRelief:  {Duration: 40 * time.Second, ScareChance: 0.01, MinScareInterval: 30 * time.Second, Intensity: 0.3, Tension: 0.1, Mood: 0.1},
+		},
+		PeakStress:    0.6,
+		MaxStress:     0.9,
+		RecoverStress: 0.3,
+		QuietDuration: 15 * time.Second,
+	}
+
+	switch difficulty {
+	case DifficultyEasy:
+		curve.scale(1.5, 0.6)
+		curve.PeakStress = 0.5
+		curve.MaxStress = 0.75
+		curve.QuietDuration = 25 * time.Second
+	case DifficultyHard:
+		curve.scale(0.75, 1.3)
+		curve.MaxStress = 0.95
+		curve.QuietDuration = 10 * time.Second
+	case DifficultyNightmare:
+		curve.scale(0.5, 1.6)
+		curve.PeakStress = 0.7
+		curve.MaxStress = 1.0
+		curve.QuietDuration = 5 * time.Second
+	}
+
+	return curve
+}
+
+// scale stretches the calm phases and multiplies the scare rates
+func (c *PacingCurve) scale(calmDuration, scareRate float64) {
+	for phase, settings := range c.Phases {
+		if phase == PhaseBuildUp || phase == PhaseRelief {
+			settings.Duration = time.Duration(float64(settings.Duration) * calmDuration)
+		}
+		settings.ScareChance = math.Min(1, settings.ScareChance*scareRate)
+		settings.MinScareInterval = time.Duration(float64(settings.MinScareInterval) / scareRate)
+		c.Phases[phase] = settings
+	}
+}
+
+// PacingState is a snapshot of the pacing model for debug tooling
+type PacingState struct {
+	Phase          PacingPhase
+	PhaseTime      time.Duration
+	Stress         float64
+	Tension        float64
+	Mood           float64
+	QuietRemaining time.Duration
+}
+
+// Pacing smoothing
+const (
+	stressDecay     = 0.97 // Stress fades when nothing happens
+	stressSanityHit = 0.15 // Stress added per point of lost sanity
+	stressHealthHit = 0.05 // Stress added per point of lost health
+	stressScareHit  = 0.5  // Stress added by a fully effective scare
+	pacingSmoothing = 0.1  // How fast tension and mood follow the phase
+)
+
+// SetDifficulty switches the pacing curve to the one of the difficulty
+func (d *Director) SetDifficulty(difficulty Difficulty) {
+	d.SetPacingCurve(PacingCurveFor(difficulty))
+}
+
+// SetPacingCurve sets a custom pacing curve; the personality reshapes it
+func (d *Director) SetPacingCurve(curve PacingCurve) {
+	d.curve = curve
+	d.pacing = d.personality.shape(curve)
+}
+
+// Pacing returns the current state of the pacing model
+func (d *Director) Pacing() PacingState {
+	return PacingState{
+		Phase:          d.phase,
+		PhaseTime:      common.Since(d.phaseStart),
+		Stress:         d.stress,
+		Tension:        d.tension,
+		Mood:           d.mood,
+		QuietRemaining: d.QuietRemaining(),
+	}
+}
+
+// QuietRemaining returns how long the current guaranteed quiet window lasts.
+// Sound and lighting should stay calm while it is above zero.
+func (d *Director) QuietRemaining() time.Duration {
+	if d.phase != PhaseRelief {
+		return 0
+	}
+	return max(0, d.pacing.QuietDuration-common.Since(d.phaseStart))
+}
+
+// InQuietWindow checks whether the player is in a guaranteed quiet window
+func (d *Director) InQuietWindow() bool {
+	return d.QuietRemaining() > 0
+}
+
+// phaseSettings returns the settings of the current phase
+func (d *Director) phaseSettings() PhaseSettings {
+	return d.pacing.Phases[d.phase]
+}
+
+// measureStress updates the player's stress from sanity and health lost since the last update
+func (d *Director) measureStress() {
+	sanityLost := math.Max(0, d.lastSanity-d.player.Sanity)
+	healthLost := math.Max(0, d.lastHealth-d.player.Health)
+	d.lastSanity = d.player.Sanity
+	d.lastHealth = d.player.Health
+
+	d.stress = clamp01(d.stress*stressDecay + sanityLost*stressSanityHit + healthLost*stressHealthHit)
+
+	// A racing heart is stress, whatever the game thinks it did to the player
+	d.stress = math.Max(d.stress, d.heartStress())
+}
+
+// addScareStress adds the stress caused by a measured scare
+func (d *Director) addScareStress(score float64) {
+	d.stress = clamp01(d.stress + score*stressScareHit)
+}
+
+// updateMoodAndTension advances the pacing cycle and moves tension and mood towards the phase
+func (d *Director) updateMoodAndTension() {
+	d.measureStress()
+
+	elapsed := common.Since(d.phaseStart)
+	settings := d.phaseSettings()
+
+	switch d.phase {
+	case PhaseBuildUp:
+		if d.stress >= d.pacing.PeakStress || elapsed >= settings.Duration {
+			d.setPhase(PhasePeak)
+		}
+
+	case PhasePeak:
+		if d.stress >= d.pacing.MaxStress {
+			d.setPhase(PhaseRelief)
+		} else if elapsed >= settings.Duration {
+			d.setPhase(PhaseSustain)
+		}
+
+	case PhaseSustain:
+		if d.stress >= d.pacing.MaxStress || elapsed >= settings.Duration {
+			d.setPhase(PhaseRelief)
+		}
+
+	case PhaseRelief:
+		if elapsed >= settings.Duration && d.stress <= d.pacing.RecoverStress {
+			d.setPhase(PhaseBuildUp)
+		}
+	}
+
+	settings = d.phaseSettings()
+	target := settings.Tension
+	if d.phase == PhaseBuildUp {
+		// Tension rises steadily through the build-up
+		progress := math.Min(1, float64(common.Since(d.phaseStart))/float64(settings.Duration))
+		target = d.pacing.Phases[PhaseRelief].Tension + (settings.Tension-d.pacing.Phases[PhaseRelief].Tension)*progress
+	}
+
+	d.tension += (target - d.tension) * pacingSmoothing
+	d.mood += (settings.Mood - d.mood) * pacingSmoothing
+	d.recordHistory()
+}
+
+// setPhase switches the pacing phase
+func (d *Director) setPhase(phase PacingPhase) {
+	d.logDecision("phase %s -> %s: stress %.2f after %.0fs", d.phase, phase, d.stress, common.Since(d.phaseStart).Seconds())
+	d.phase = phase
+	d.phaseStart = common.Now()
+}
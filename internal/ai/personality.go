@@ -0,0 +1,294 @@
+package ai
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+// Personality is a style of directing layered over the difficulty. It weighs the choice
+// of scares and creatures and reshapes the pacing curve, so that replays feel different.
+type Personality struct {
+	Name        string
+	Description string
+
+	EventWeights    map[common.ScareEventType]float64 // Multiplies the score of each event type; missing types keep 1, 0 forbids a type
+	CreatureWeights map[string]float64                // Relative chance of each creature type; nil keeps the usual choice
+
+	ScareRate      float64 // Multiplies the chance of a scare
+	IntervalScale  float64 // Multiplies the minimum time between scares
+	IntensityScale float64 // Multiplies the scare intensity of every phase
+	CalmScale      float64 // Stretches the build-up and relief phases and the quiet window
+
+	FalseAlarms float64 // Chance that a creature appearance turns out to be a hallucination
+	Stalker     bool    // One creature keeps hunting the player instead of new ones appearing
+}
+
+// stalkerSearchRadius is how far from the requested point a spawned stalker is looked for
+const stalkerSearchRadius = 30.0
+
+// Personality names
+const (
+	PersonalityBalanced  = "balanced"
+	PersonalitySlowBurn  = "slow-burn"
+	PersonalityTrickster = "trickster"
+	PersonalityPredator  = "predator"
+	PersonalityChaos     = "chaos"
+)
+
+// Personalities returns every director personality, the balanced one first
+func Personalities() []Personality {
+	return []Personality{
+		{
+			Name:           PersonalityBalanced,
+			Description:    "Follows the difficulty as it is",
+			ScareRate:      1,
+			IntervalScale:  1,
+			IntensityScale: 1,
+			CalmScale:      1,
+		},
+		{
+			Name:        PersonalitySlowBurn,
+			Description: "Long silences broken by rare, heavy scares",
+			EventWeights: map[common.ScareEventType]float64{
+				common.EventAmbientSound:       0.5,
+				common.EventSuddenNoise:        1.2,
+				common.EventCreatureAppearance: 1.5,
+				common.EventEnvironmentChange:  1.3,
+				common.EventHallucination:      0.8,
+				common.EventWhisper:            0.6,
+			},
+			ScareRate:      0.4,
+			IntervalScale:  2.5,
+			IntensityScale: 1.4,
+			CalmScale:      1.8,
+		},
+		{
+			Name:        PersonalityTrickster,
+			Description: "Hallucinations and false alarms; what appears is rarely there",
+			EventWeights: map[common.ScareEventType]float64{
+				common.EventAmbientSound:       1,
+				common.EventSuddenNoise:        1.5,
+				common.EventCreatureAppearance: 0.4,
+				common.EventEnvironmentChange:  0.8,
+				common.EventHallucination:      3,
+				common.EventWhisper:            2,
+			},
+			CreatureWeights: map[string]float64{"phantom": 3, "doppelganger": 2, "shadow": 1},
+			ScareRate:       1.2,
+			IntervalScale:   0.8,
+			IntensityScale:  0.9,
+			CalmScale:       1,
+			FalseAlarms:     0.6,
+		},
+		{
+			Name:        PersonalityPredator,
+			Description: "One persistent stalker hunts the player",
+			EventWeights: map[common.ScareEventType]float64{
+				common.EventAmbientSound:       1.3,
+				common.EventSuddenNoise:        1,
+				common.EventCreatureAppearance: 2,
+				common.EventEnvironmentChange:  0.6,
+				common.EventHallucination:      0.5,
+				common.EventWhisper:            0.8,
+			},
+			CreatureWeights: map[string]float64{"wendigo": 3, "faceless": 2, "shadow": 1},
+			ScareRate:       1,
+			IntervalScale:   1,
+			IntensityScale:  1.1,
+			CalmScale:       1.2,
+			Stalker:         true,
+		},
+		{
+			Name:           PersonalityChaos,
+			Description:    "Constant small scares of every kind",
+			ScareRate:      3,
+			IntervalScale:  0.35,
+			IntensityScale: 0.5,
+			CalmScale:      0.5,
+		},
+	}
+}
+
+// FindPersonality returns the personality with the given name
+func FindPersonality(name string) (Personality, bool) {
+	for _, personality := range Personalities() {
+		if strings.EqualFold(personality.Name, name) {
+			return personality, true
+		}
+	}
+	return Personality{}, false
+}
+
+// RandomPersonality rolls a personality
+func RandomPersonality(random *rand.Rand) Personality {
+	personalities := Personalities()
+	return personalities[random.Intn(len(personalities))]
+}
+
+// DefaultPersonality returns the balanced personality
+func DefaultPersonality() Personality {
+	return Personalities()[0]
+}
+
+// eventWeight returns the weight of an event type
+func (p Personality) eventWeight(eventType common.ScareEventType) float64 {
+	if weight, ok := p.EventWeights[eventType]; ok {
+		return weight
+	}
+	return 1
+}
+
+// shape applies the personality to a pacing curve, leaving the original untouched
+func (p Personality) shape(curve PacingCurve) PacingCurve {
+	phases := make(map[PacingPhase]PhaseSettings, len(curve.Phases))
+	for phase, settings := range curve.Phases {
+		if phase == PhaseBuildUp || phase == PhaseRelief {
+			settings.Duration = time.Duration(float64(settings.Duration) * p.CalmScale)
+		}
+		settings.ScareChance = math.Min(1, settings.ScareChance*p.ScareRate)
+		settings.MinScareInterval = time.Duration(float64(settings.MinScareInterval) * p.IntervalScale)
+		settings.Intensity = math.Min(1, settings.Intensity*p.IntensityScale)
+		phases[phase] = settings
+	}
+
+	curve.Phases = phases
+	curve.QuietDuration = time.Duration(float64(curve.QuietDuration) * p.CalmScale)
+	return curve
+}
+
+// SetPersonality switches the director's personality
+func (d *Director) SetPersonality(personality Personality) {
+	d.personality = personality
+	d.pacing = personality.shape(d.curve)
+	d.stalker = nil
+	d.logDecision("personality: %s, %s", personality.Name, strings.ToLower(personality.Description))
+}
+
+// Personality returns the director's personality
+func (d *Director) Personality() Personality {
+	return d.personality
+}
+
+// choosePersonalityCreature picks a creature type by the personality's weights among the candidates.
+// Returns false if the personality has no preference among them.
+func (d *Director) choosePersonalityCreature(candidates []string) (string, bool) {
+	weights := d.personality.CreatureWeights
+	if len(weights) == 0 {
+		return "", false
+	}
+
+	total := 0.0
+	for _, creatureType := range candidates {
+		total += weights[creatureType]
+	}
+
+	// None of the candidates suits the personality, so its own favorites come out
+	if total == 0 {
+		candidates = candidates[:0:0]
+		for creatureType := range weights {
+			candidates = append(candidates, creatureType)
+			total += weights[creatureType]
+		}
+		sort.Strings(candidates)
+		if total == 0 {
+			return "", false
+		}
+	}
+
+	roll := common.Rand().Float64() * total
+	for _, creatureType := range candidates {
+		roll -= weights[creatureType]
+		if roll < 0 {
+			return creatureType, true
+		}
+	}
+	return candidates[len(candidates)-1], true
+}
+
+// raiseFalseAlarm turns some creature appearances into hallucinations of the same creature
+func (d *Director) raiseFalseAlarm(event *common.ScareEvent) {
+	if event.Type != common.EventCreatureAppearance || common.Rand().Float64() >= d.personality.FalseAlarms {
+		return
+	}
+
+	d.logDecision("%s: %s at (%.0f, %.0f) is a false alarm",
+		d.personality.Name, event.CreatureType, event.Position.X, event.Position.Y)
+	event.Type = common.EventHallucination
+	event.Path = nil
+}
+
+// huntWithStalker sends the personality's stalker after the player instead of spawning a new creature.
+// Returns false if there is no stalker to send.
+func (d *Director) huntWithStalker() bool {
+	if !d.personality.Stalker || !d.stalkerActive() {
+		return false
+	}
+
+	d.stalker.SetTarget(d.player)
+	d.logDecision("%s: the %s #%d picks up the player's trail", d.personality.Name, d.stalker.Type, d.stalker.ID)
+	return true
+}
+
+// adoptStalker makes the creature just spawned for the event the personality's stalker
+func (d *Director) adoptStalker(event common.ScareEvent) {
+	if !d.personality.Stalker || d.stalkerActive() {
+		return
+	}
+
+	population, ok := d.world.(interface {
+		CreaturesNear(common.Vector2D, float64) []*entity.Creature
+	})
+	if !ok {
+		return
+	}
+
+	// The newest creature of the type near the spawn point is the one just created
+	var newest *entity.Creature
+	for _, creature := range population.CreaturesNear(event.Position, stalkerSearchRadius) {
+		if creature.Type == event.CreatureType && (newest == nil || creature.ID > newest.ID) {
+			newest = creature
+		}
+	}
+	if newest != nil {
+		d.stalker = newest
+		d.logDecision("%s: the %s #%d becomes the stalker", d.personality.Name, newest.Type, newest.ID)
+	}
+}
+
+// keepHunting keeps the stalker on the player between scares, unless the player must be spared
+func (d *Director) keepHunting() {
+	if !d.personality.Stalker || !d.stalkerActive() || d.stalker.PlayerTarget != nil {
+		return
+	}
+	if d.InGrace() || d.InQuietWindow() || d.player.Health < d.fairness.CriticalHealth {
+		return
+	}
+
+	d.stalker.SetTarget(d.player)
+}
+
+// stalkerActive checks that the stalker is alive and roams the world rather than hibernating
+func (d *Director) stalkerActive() bool {
+	if d.stalker == nil || d.stalker.IsDead() {
+		return false
+	}
+
+	population, ok := d.world.(interface {
+		Creatures() []*entity.Creature
+	})
+	if !ok {
+		return false
+	}
+	for _, creature := range population.Creatures() {
+		if creature == d.stalker {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"math"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+const (
+	ambushCandidates  = 32          // Random points considered around the player
+	ambushMinDistance = 6.0         // Ambushes are never closer than this
+	ambushMaxDistance = 25.0        // Ambushes are never farther than this
+	predictDistance   = 25.0        // How far ahead the player's path is predicted
+	pathWidth         = 8.0         // Points farther than this from the predicted path get no path score
+	chokeRadius       = 2.0         // Radius at which surroundings are probed for a chokepoint
+	lightEdgeBand     = math.Pi / 9 // Angle beyond the flashlight cone that counts as its edge
+	nearSight         = 4.0         // Even in darkness the player sees this far
+	revealChance      = 0.25        // Chance that a peak creature appearance is meant to be seen
+	anchorCandidates  = 8           // Points considered around the recommended anchor
+	anchorRadius      = 6.0         // Spread of the points around the anchor
+	anchorReach       = 20.0        // Points farther than this from the anchor get no anchor score
+)
+
+// Weights of the ambush score components
+const (
+	heatWeight   = 0.3
+	pathWeight   = 0.25
+	chokeWeight  = 0.15
+	edgeWeight   = 0.1
+	anchorWeight = 0.2
+)
+
+// ambushSpot is a candidate position and why it is good
+type ambushSpot struct {
+	position common.Vector2D
+	heat     float64
+	path     float64
+	choke    float64
+	edge     float64
+	anchor   float64
+	score    float64
+}
+
+// placeAmbush finds the best spot for a creature or environment change: on the player's
+// favorite routes, at chokepoints ahead of them, just outside the flashlight beam and
+// near the anchor the observer recommended. Unless the scare is a reveal, spots the
+// player can see are rejected.
+func (d *Director) placeAmbush(reveal bool, anchor entity.Vector2D) (ambushSpot, bool) {
+	worldObj, ok := d.world.(interface {
+		IsBlocked(common.Vector2D) bool
+		HasLineOfSight(from, to common.Vector2D) bool
+	})
+	if !ok {
+		return ambushSpot{}, false
+	}
+
+	// Only a recommended anchor counts: without one it stays by the player, whom ambushes keep away from
+	anchored := d.recommendation() != nil
+
+	best := ambushSpot{score: -1}
+	for _, position := range d.ambushCandidates(anchor, anchored) {
+		if worldObj.IsBlocked(position) {
+			continue
+		}
+		if d.playerSees(position, worldObj.HasLineOfSight) != reveal {
+			continue
+		}
+
+		spot := d.scoreSpot(position, worldObj.IsBlocked)
+		if anchored {
+			spot.anchor = 1 - clamp01(common.Distance(position, anchor.ToCommonVector())/anchorReach)
+			spot.score += spot.anchor * anchorWeight
+		}
+		if spot.score > best.score {
+			best = spot
+		}
+	}
+
+	return best, best.score >= 0
+}
+
+// ambushCandidates returns random points around the player, along the predicted path,
+// at the edge of the beam, in the player's favorite places and around the recommended anchor
+func (d *Director) ambushCandidates(anchor entity.Vector2D, anchored bool) []common.Vector2D {
+	origin := d.player.Position
+	facing := d.player.Direction
+
+	at := func(angle, dist float64) common.Vector2D {
+		return common.Vector2D{X: origin.X + math.Cos(angle)*dist, Y: origin.Y + math.Sin(angle)*dist}
+	}
+	randomDistance := func() float64 {
+		return ambushMinDistance + common.Rand().Float64()*(ambushMaxDistance-ambushMinDistance)
+	}
+
+	candidates := make([]common.Vector2D, 0, ambushCandidates+16+anchorCandidates)
+	for i := 0; i < ambushCandidates; i++ {
+		candidates = append(candidates, at(common.Rand().Float64()*2*math.Pi, randomDistance()))
+	}
+
+	for i := 0; i < 8; i++ {
+		// Ahead on the predicted path, slightly to either side
+		candidates = append(candidates, at(facing+(common.Rand().Float64()-0.5)*0.3, randomDistance()))
+
+		// Just beyond the edge of the flashlight beam
+		side := 1.0
+		if i%2 == 0 {
+			side = -1
+		}
+		angle := facing + side*(entity.FlashlightAngle/2+common.Rand().Float64()*lightEdgeBand)
+		candidates = append(candidates, at(angle, ambushMinDistance+common.Rand().Float64()*(entity.FlashlightRange-ambushMinDistance)))
+	}
+
+	// The player's favorite places nearby
+	if d.analyzer != nil {
+		for _, area := range d.analyzer.GetMovementAnalysis().PreferredAreas {
+			if distance(area, origin) <= ambushMaxDistance {
+				candidates = append(candidates, area.ToCommonVector())
+			}
+		}
+	}
+
+	// The place the observer recommended and its surroundings
+	if anchored {
+		candidates = append(candidates, anchor.ToCommonVector())
+		for i := 1; i < anchorCandidates; i++ {
+			angle := common.Rand().Float64() * 2 * math.Pi
+			dist := common.Rand().Float64() * anchorRadius
+			candidates = append(candidates, common.Vector2D{X: anchor.X + math.Cos(angle)*dist, Y: anchor.Y + math.Sin(angle)*dist})
+		}
+	}
+
+	return candidates
+}
+
+// playerSees checks whether the player would see something standing at the position
+func (d *Director) playerSees(position common.Vector2D, lineOfSight func(from, to common.Vector2D) bool) bool {
+	point := entity.FromCommonVector(position)
+	if distance(point, d.player.Position) > nearSight && !d.player.FlashlightFrustum().Contains(point) {
+		return false
+	}
+	return lineOfSight(d.player.Position.ToCommonVector(), position)
+}
+
+// scoreSpot rates a position for an ambush
+func (d *Director) scoreSpot(position common.Vector2D, blocked func(common.Vector2D) bool) ambushSpot {
+	spot := ambushSpot{position: position}
+
+	if d.analyzer != nil {
+		spot.heat = d.analyzer.Heat(entity.FromCommonVector(position))
+	}
+	spot.path = 1 - clamp01(d.distanceToPredictedPath(position)/pathWidth)
+	spot.choke = chokepoint(position, blocked)
+	if d.atLightEdge(position) {
+		spot.edge = 1
+	}
+
+	spot.score = spot.heat*heatWeight + spot.path*pathWeight + spot.choke*chokeWeight + spot.edge*edgeWeight
+	return spot
+}
+
+// distanceToPredictedPath returns how far a point is from the straight path ahead of the player
+func (d *Director) distanceToPredictedPath(position common.Vector2D) float64 {
+	origin := d.player.Position
+	dirX, dirY := math.Cos(d.player.Direction), math.Sin(d.player.Direction)
+
+	// Project the point onto the path and clamp to its length
+	along := (position.X-origin.X)*dirX + (position.Y-origin.Y)*dirY
+	along = math.Max(0, math.Min(predictDistance, along))
+
+	closest := common.Vector2D{X: origin.X + dirX*along, Y: origin.Y + dirY*along}
+	return common.Distance(position, closest)
+}
+
+// chokepoint rates how much a position is a narrow passage: open where it stands,
+// with solid ground on some but not all sides
+func chokepoint(position common.Vector2D, blocked func(common.Vector2D) bool) float64 {
+	const probes = 8
+
+	solid := 0
+	for i := 0; i < probes; i++ {
+		angle := float64(i) * 2 * math.Pi / probes
+		probe := common.Vector2D{
+			X: position.X + math.Cos(angle)*chokeRadius,
+			Y: position.Y + math.Sin(angle)*chokeRadius,
+		}
+		if blocked(probe) {
+			solid++
+		}
+	}
+
+	// Half the sides blocked is a perfect passage; fully open or fully closed is not
+	return 1 - math.Abs(float64(solid)/probes-0.5)*2
+}
+
+// atLightEdge checks whether a position lies in the darkness just beside the flashlight beam
+func (d *Director) atLightEdge(position common.Vector2D) bool {
+	point := entity.FromCommonVector(position)
+	if distance(point, d.player.Position) > entity.FlashlightRange {
+		return false
+	}
+
+	angle := math.Atan2(position.Y-d.player.Position.Y, position.X-d.player.Position.X)
+	offset := math.Abs(math.Remainder(angle-d.player.Direction, 2*math.Pi))
+	return offset > entity.FlashlightAngle/2 && offset <= entity.FlashlightAngle/2+lightEdgeBand
+}
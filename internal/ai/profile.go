@@ -0,0 +1,177 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+const (
+	profileHalfLife = 7 * 24 * time.Hour // After this long away, learned values are halfway back to neutral
+	profileNeutral  = 0.5                // Neutral value of every profile entry
+)
+
+// PlayerProfile is what the director has learned about a player, kept between sessions
+type PlayerProfile struct {
+	FearProfile        map[FearType]float64              `json:"fear_profile"`
+	ReactorProfile     map[ReactorType]float64           `json:"reactor_profile"`
+	Behavior           BehaviorPattern                   `json:"behavior"`
+	ScareEffectiveness map[common.ScareEventType]float64 `json:"scare_effectiveness"`
+	Bandit             *ScareBandit                      `json:"bandit,omitempty"`
+	KeptGenomes        []*entity.Genome                  `json:"kept_genomes,omitempty"` // The scariest creatures, bred from again
+	SavedAt            time.Time                         `json:"saved_at"`
+}
+
+// DefaultProfilePath returns the location of the local player profile
+func DefaultProfilePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "nightmare", "profile.json")
+}
+
+// LoadPlayerProfile reads a player profile. A missing file is reported as os.ErrNotExist.
+func LoadPlayerProfile(path string) (*PlayerProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &PlayerProfile{}
+	if err := json.Unmarshal(data, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Save writes the profile, replacing the previous one only once it is fully written
+func (p *PlayerProfile) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Export writes the profile as readable JSON
+func (p *PlayerProfile) Export(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+// ResetPlayerProfile deletes the saved profile so the next session starts from scratch
+func ResetPlayerProfile(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Decay moves learned values toward neutral according to how long the player has been away
+func (p *PlayerProfile) Decay(now time.Time) {
+	away := now.Sub(p.SavedAt)
+	if away <= 0 {
+		return
+	}
+
+	keep := math.Pow(0.5, float64(away)/float64(profileHalfLife))
+	toNeutral := func(value float64) float64 {
+		return profileNeutral + (value-profileNeutral)*keep
+	}
+
+	for fearType, value := range p.FearProfile {
+		p.FearProfile[fearType] = toNeutral(value)
+	}
+	for reactorType, value := range p.ReactorProfile {
+		p.ReactorProfile[reactorType] = toNeutral(value)
+	}
+	for eventType, value := range p.ScareEffectiveness {
+		p.ScareEffectiveness[eventType] = toNeutral(value)
+	}
+
+	p.Behavior.MovementPreference = toNeutral(p.Behavior.MovementPreference)
+	p.Behavior.ExplorationPreference = toNeutral(p.Behavior.ExplorationPreference)
+	p.Behavior.RiskTolerance = toNeutral(p.Behavior.RiskTolerance)
+	p.Behavior.ReactivityToScares = toNeutral(p.Behavior.ReactivityToScares)
+
+	if p.Bandit != nil {
+		p.Bandit.Decay(keep)
+	}
+}
+
+// Profile captures what the director and its observer have learned about the player
+func (d *Director) Profile() *PlayerProfile {
+	profile := &PlayerProfile{
+		FearProfile:        make(map[FearType]float64),
+		ReactorProfile:     make(map[ReactorType]float64),
+		Behavior:           d.playerBehavior,
+		ScareEffectiveness: make(map[common.ScareEventType]float64),
+		Bandit:             d.bandit,
+		SavedAt:            common.Now(),
+	}
+
+	for _, genome := range d.keptGenomes {
+		profile.KeptGenomes = append(profile.KeptGenomes, genome.Clone())
+	}
+
+	for eventType, value := range d.scareEffectiveness {
+		profile.ScareEffectiveness[eventType] = value
+	}
+
+	if d.observer != nil {
+		for fearType, value := range d.observer.GetPlayerFearProfile() {
+			profile.FearProfile[fearType] = value
+		}
+		for reactorType, value := range d.observer.GetPlayerReactorProfile() {
+			profile.ReactorProfile[reactorType] = value
+		}
+	}
+
+	return profile
+}
+
+// ApplyProfile starts the director from a profile saved in an earlier session
+func (d *Director) ApplyProfile(profile *PlayerProfile) {
+	d.playerBehavior = profile.Behavior
+	if d.playerBehavior.PreferredInteractions == nil {
+		d.playerBehavior.PreferredInteractions = []string{}
+	}
+
+	for eventType, value := range profile.ScareEffectiveness {
+		d.scareEffectiveness[eventType] = value
+	}
+
+	if profile.Bandit != nil && profile.Bandit.Contexts != nil && profile.Bandit.Global != nil {
+		d.bandit = profile.Bandit
+	}
+
+	// Creatures that scared the player before come back, bred anew
+	for _, genome := range profile.KeptGenomes {
+		if genome != nil {
+			d.KeepGenome(genome)
+		}
+	}
+
+	if d.observer != nil {
+		d.observer.LoadProfiles(profile.FearProfile, profile.ReactorProfile)
+	}
+}
@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"math"
+	"time"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+// SetPresenter sets the object that turns scare events into sound, screen effects and world changes
+func (d *Director) SetPresenter(presenter interface{}) {
+	d.presenter = presenter
+}
+
+// placeScare positions a sensory scare around the anchor the observer recommended,
+// or around the player without a recommendation, and sets how long it lasts
+func (d *Director) placeScare(event *common.ScareEvent, anchor entity.Vector2D) {
+	origin := d.player.Position
+	if d.recommendation() != nil {
+		origin = anchor
+	}
+	facing := d.player.Direction
+
+	var angle, dist float64
+	var duration time.Duration
+
+	switch event.Type {
+	case common.EventAmbientSound:
+		// Something stirs somewhere out in the forest
+		angle = common.Rand().Float64() * 2 * math.Pi
+		dist = 15 + common.Rand().Float64()*15
+		duration = randomDuration(4*time.Second, 10*time.Second)
+
+	case common.EventSuddenNoise:
+		// A sharp noise right behind the player
+		angle = facing + math.Pi + (common.Rand().Float64()-0.5)*math.Pi/2
+		dist = 4 + common.Rand().Float64()*4
+		duration = randomDuration(500*time.Millisecond, 1500*time.Millisecond)
+
+	case common.EventWhisper:
+		// A voice at one ear
+		side := math.Pi / 2
+		if common.Rand().Intn(2) == 0 {
+			side = -side
+		}
+		angle = facing + side
+		dist = 1.5 + common.Rand().Float64()*1.5
+		duration = randomDuration(2*time.Second, 4*time.Second)
+
+	case common.EventHallucination:
+		// A figure where the player is looking
+		angle = facing + (common.Rand().Float64()-0.5)*math.Pi/4
+		dist = 6 + common.Rand().Float64()*6
+		duration = randomDuration(2*time.Second, 5*time.Second)
+		event.CreatureType = d.chooseCreatureType()
+
+	default:
+		return
+	}
+
+	event.Position = common.Vector2D{
+		X: origin.X + math.Cos(angle)*dist,
+		Y: origin.Y + math.Sin(angle)*dist,
+	}
+
+	// Stronger scares linger longer
+	event.Duration = time.Duration(float64(duration) * (0.5 + event.Intensity))
+}
+
+// randomDuration returns a random duration in the range
+func randomDuration(from, to time.Duration) time.Duration {
+	return from + time.Duration(common.Rand().Int63n(int64(to-from)))
+}
@@ -0,0 +1,384 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"nightmare/internal/common"
+)
+
+// Sequence triggers
+const (
+	TriggerZoneEntered  = "zone_entered"   // Player entered a zone of type Zone
+	TriggerSanityBelow  = "sanity_below"   // Player's sanity dropped below Value
+	TriggerItemPickedUp = "item_picked_up" // Player picked up the item named Item
+)
+
+// Sequence cancel conditions
+const (
+	CancelSanityBelow   = "sanity_below"   // Player's sanity dropped below Value
+	CancelHealthBelow   = "health_below"   // Player's health dropped below Value
+	CancelDistanceMoved = "distance_moved" // Player moved farther than Value from where the sequence started
+	CancelPhase         = "phase"          // Pacing switched to Phase
+)
+
+// Sequence step actions
+const (
+	ActionFlicker       = "flicker"       // Lights flicker
+	ActionSilence       = "silence"       // Every sound stops
+	ActionAmbient       = "ambient"       // Distant sound
+	ActionSuddenNoise   = "sudden_noise"  // Sharp noise
+	ActionWhisper       = "whisper"       // Whisper
+	ActionHallucination = "hallucination" // Imagined creature
+	ActionCreature      = "creature"      // Real creature
+	ActionEnvironment   = "environment"   // Environment change
+)
+
+// stepEventTypes maps step actions to the scare events they produce
+var stepEventTypes = map[string]common.ScareEventType{
+	ActionAmbient:       common.EventAmbientSound,
+	ActionSuddenNoise:   common.EventSuddenNoise,
+	ActionWhisper:       common.EventWhisper,
+	ActionHallucination: common.EventHallucination,
+	ActionCreature:      common.EventCreatureAppearance,
+	ActionEnvironment:   common.EventEnvironmentChange,
+}
+
+// ScareSequence is an authored multi-step scare loaded from a data file
+type ScareSequence struct {
+	Name     string              `json:"name"`
+	Trigger  SequenceTrigger     `json:"trigger"`
+	Steps    []SequenceStep      `json:"steps"`
+	Cancel   []SequenceCondition `json:"cancel"`
+	Phases   []string            `json:"phases"`   // Pacing phases the sequence may start in; empty means any but relief
+	Cooldown float64             `json:"cooldown"` // Seconds before the sequence may run again
+	Once     bool                `json:"once"`     // Run at most once per session
+
+	lastRun time.Time
+	runs    int
+}
+
+// SequenceTrigger starts a sequence
+type SequenceTrigger struct {
+	Type  string  `json:"type"`
+	Zone  string  `json:"zone,omitempty"`
+	Item  string  `json:"item,omitempty"`
+	Value float64 `json:"value,omitempty"`
+}
+
+// SequenceCondition cancels a running sequence
+type SequenceCondition struct {
+	Type  string  `json:"type"`
+	Phase string  `json:"phase,omitempty"`
+	Value float64 `json:"value,omitempty"`
+}
+
+// SequenceStep is one beat of a sequence
+type SequenceStep struct {
+	Action       string  `json:"action"`
+	Delay        float64 `json:"delay"`    // Seconds after the previous step
+	Duration     float64 `json:"duration"` // Seconds the step lasts
+	Intensity    float64 `json:"intensity"`
+	Distance     float64 `json:"distance"` // Tiles from the player
+	Angle        float64 `json:"angle"`    // Degrees from the player's facing: 0 ahead, 180 behind
+	CreatureType string  `json:"creature,omitempty"`
+}
+
+// runningSequence is a sequence in progress
+type runningSequence struct {
+	sequence *ScareSequence
+	step     int
+	nextAt   time.Time
+	origin   common.Vector2D
+}
+
+// LoadScareSequences reads every .json sequence file in the directory
+func LoadScareSequences(dir string) ([]*ScareSequence, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	sequences := []*ScareSequence{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		sequence := &ScareSequence{}
+		if err := json.Unmarshal(data, sequence); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if err := sequence.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		sequences = append(sequences, sequence)
+	}
+
+	return sequences, nil
+}
+
+// validate checks that the sequence only uses known triggers and actions
+func (s *ScareSequence) validate() error {
+	switch s.Trigger.Type {
+	case TriggerZoneEntered, TriggerSanityBelow, TriggerItemPickedUp:
+	default:
+		return fmt.Errorf("sequence %q: unknown trigger %q", s.Name, s.Trigger.Type)
+	}
+
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("sequence %q has no steps", s.Name)
+	}
+
+	for i, step := range s.Steps {
+		if _, ok := stepEventTypes[step.Action]; !ok && step.Action != ActionFlicker && step.Action != ActionSilence {
+			return fmt.Errorf("sequence %q step %d: unknown action %q", s.Name, i, step.Action)
+		}
+	}
+
+	for _, condition := range s.Cancel {
+		switch condition.Type {
+		case CancelSanityBelow, CancelHealthBelow, CancelDistanceMoved:
+		case CancelPhase:
+			if !knownPhase(condition.Phase) {
+				return fmt.Errorf("sequence %q: unknown phase %q", s.Name, condition.Phase)
+			}
+		default:
+			return fmt.Errorf("sequence %q: unknown cancel condition %q", s.Name, condition.Type)
+		}
+	}
+
+	for _, phase := range s.Phases {
+		if !knownPhase(phase) {
+			return fmt.Errorf("sequence %q: unknown phase %q", s.Name, phase)
+		}
+	}
+
+	return nil
+}
+
+// knownPhase checks that a name belongs to a pacing phase
+func knownPhase(name string) bool {
+	for phase := PhaseBuildUp; phase <= PhaseRelief; phase++ {
+		if phase.String() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AddSequences makes authored sequences available to the director
+func (d *Director) AddSequences(sequences []*ScareSequence) {
+	d.sequences = append(d.sequences, sequences...)
+}
+
+// ItemPickedUp tells the director that the player picked up an item
+func (d *Director) ItemPickedUp(name string) {
+	d.pickedUp = append(d.pickedUp, name)
+}
+
+// SequenceRunning checks whether an authored sequence is playing
+func (d *Director) SequenceRunning() bool {
+	return d.running != nil
+}
+
+// UpdateSequences advances the running sequence or starts a triggered one.
+// It is called every frame so that steps keep their authored timing.
+func (d *Director) UpdateSequences() {
+	zone := d.playerZone()
+	enteredZone := zone != d.lastZone
+	d.lastZone = zone
+
+	pickedUp := d.pickedUp
+	d.pickedUp = nil
+
+	if d.running != nil {
+		d.advanceSequence()
+		return
+	}
+
+	// Sequences respect pacing: never during a quiet window
+	if d.InQuietWindow() {
+		return
+	}
+
+	for _, sequence := range d.sequences {
+		if !d.canStart(sequence) {
+			continue
+		}
+		if d.triggered(sequence.Trigger, enteredZone, zone, pickedUp) {
+			d.startSequence(sequence)
+			return
+		}
+	}
+}
+
+// canStart checks cooldown, repetition and pacing phase of a sequence
+func (d *Director) canStart(sequence *ScareSequence) bool {
+	if sequence.Once && sequence.runs > 0 {
+		return false
+	}
+	if sequence.runs > 0 && common.Since(sequence.lastRun) < time.Duration(sequence.Cooldown*float64(time.Second)) {
+		return false
+	}
+
+	if len(sequence.Phases) == 0 {
+		return d.phase != PhaseRelief
+	}
+	for _, phase := range sequence.Phases {
+		if phase == d.phase.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// triggered checks the trigger of a sequence
+func (d *Director) triggered(trigger SequenceTrigger, enteredZone bool, zone string, pickedUp []string) bool {
+	switch trigger.Type {
+	case TriggerZoneEntered:
+		return enteredZone && zone == trigger.Zone
+	case TriggerSanityBelow:
+		return d.player.Sanity < trigger.Value
+	case TriggerItemPickedUp:
+		for _, name := range pickedUp {
+			if name == trigger.Item {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// startSequence begins playing a sequence
+func (d *Director) startSequence(sequence *ScareSequence) {
+	d.logDecision("started sequence %s: %s trigger, phase %s", sequence.Name, sequence.Trigger.Type, d.phase)
+	sequence.runs++
+	sequence.lastRun = common.Now()
+
+	d.running = &runningSequence{
+		sequence: sequence,
+		nextAt:   common.Now().Add(seconds(sequence.Steps[0].Delay)),
+		origin:   d.player.Position.ToCommonVector(),
+	}
+}
+
+// advanceSequence plays the steps that are due and checks the cancel conditions
+func (d *Director) advanceSequence() {
+	running := d.running
+	if condition, ok := d.cancelled(running); ok {
+		d.logDecision("cancelled sequence %s at step %d: %s", running.sequence.Name, running.step, condition.Type)
+		d.running = nil
+		return
+	}
+
+	if common.Now().Before(running.nextAt) {
+		return
+	}
+
+	steps := running.sequence.Steps
+	d.logDecision("sequence %s step %d: %s", running.sequence.Name, running.step, steps[running.step].Action)
+	d.playStep(steps[running.step])
+
+	running.step++
+	if running.step >= len(steps) {
+		d.running = nil
+		return
+	}
+
+	running.nextAt = common.Now().Add(seconds(steps[running.step].Delay))
+}
+
+// cancelled checks the cancel conditions of a running sequence and returns the one that was met
+func (d *Director) cancelled(running *runningSequence) (SequenceCondition, bool) {
+	for _, condition := range running.sequence.Cancel {
+		switch condition.Type {
+		case CancelSanityBelow:
+			if d.player.Sanity < condition.Value {
+				return condition, true
+			}
+		case CancelHealthBelow:
+			if d.player.Health < condition.Value {
+				return condition, true
+			}
+		case CancelDistanceMoved:
+			if common.Distance(running.origin, d.player.Position.ToCommonVector()) > condition.Value {
+				return condition, true
+			}
+		case CancelPhase:
+			if d.phase.String() == condition.Phase {
+				return condition, true
+			}
+		}
+	}
+	return SequenceCondition{}, false
+}
+
+// playStep turns a step into output, placing it relative to where the player is facing
+func (d *Director) playStep(step SequenceStep) {
+	angle := d.player.Direction + step.Angle*math.Pi/180
+	position := common.Vector2D{
+		X: d.player.Position.X + math.Cos(angle)*step.Distance,
+		Y: d.player.Position.Y + math.Sin(angle)*step.Distance,
+	}
+	duration := seconds(step.Duration)
+
+	switch step.Action {
+	case ActionFlicker:
+		if presenter, ok := d.presenter.(interface {
+			FlickerLights(time.Duration)
+		}); ok {
+			presenter.FlickerLights(duration)
+		}
+		return
+
+	case ActionSilence:
+		if presenter, ok := d.presenter.(interface {
+			Silence(time.Duration)
+		}); ok {
+			presenter.Silence(duration)
+		}
+		return
+	}
+
+	event := common.ScareEvent{
+		Type:         stepEventTypes[step.Action],
+		Intensity:    clamp01(step.Intensity),
+		Position:     position,
+		Duration:     duration,
+		CreatureType: step.CreatureType,
+		Timestamp:    common.Now(),
+	}
+	if event.CreatureType == "" && (event.Type == common.EventCreatureAppearance || event.Type == common.EventHallucination) {
+		event.CreatureType = d.chooseCreatureType()
+	}
+
+	d.executeScareEvent(event, d.banditContext())
+}
+
+// playerZone returns the type of the zone the player is in, or an empty string
+func (d *Director) playerZone() string {
+	return d.zoneNameAt(d.player.Position.ToCommonVector())
+}
+
+// zoneNameAt returns the type of the zone containing the position, or an empty string
+func (d *Director) zoneNameAt(position common.Vector2D) string {
+	worldObj, ok := d.world.(interface {
+		ZoneNameAt(common.Vector2D) string
+	})
+	if !ok {
+		return ""
+	}
+	return worldObj.ZoneNameAt(position)
+}
+
+// seconds converts seconds to a duration
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,118 @@
+// Package biometric reads the player's heart rate from a bridge process running next to
+// the game, or simulates it, so the director and the heartbeat sound can follow the real pulse.
+package biometric
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	staleAfter   = 5 * time.Second // Readings older than this count as a lost signal
+	minHeartRate = 25              // Readings outside this range are sensor glitches
+	maxHeartRate = 250
+)
+
+// BiometricSource provides the player's heart rate, for example from a bridge process
+// streaming a chest strap during playtests
+type BiometricSource interface {
+	// HeartRate returns the latest heart rate in beats per minute; ok is false without a fresh reading
+	HeartRate() (bpm float64, ok bool)
+
+	// Close stops reading
+	Close() error
+}
+
+// Open creates a source from a description:
+//
+//	sim           simulated heart rate
+//	file:PATH     last line of a file a bridge keeps rewriting
+//	udp:PORT      datagrams sent to a local port (udp:HOST:PORT for a specific loopback address)
+//	ws://HOST/... messages of a local WebSocket server
+func Open(spec string) (BiometricSource, error) {
+	switch {
+	case spec == "sim":
+		return NewSimulated(defaultRestingRate, time.Now().UnixNano()), nil
+	case strings.HasPrefix(spec, "file:"):
+		return OpenFile(strings.TrimPrefix(spec, "file:"))
+	case strings.HasPrefix(spec, "udp:"):
+		return ListenUDP(strings.TrimPrefix(spec, "udp:"))
+	case strings.HasPrefix(spec, "ws://"):
+		return DialWebSocket(spec)
+	default:
+		return nil, fmt.Errorf("unknown heart rate source %q", spec)
+	}
+}
+
+// ParseReading extracts a heart rate from one message of a bridge. It accepts a bare number,
+// a CSV line whose last field is the rate ("1697040000,82") and JSON with a heart_rate, bpm or hr field.
+func ParseReading(text string) (float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, fmt.Errorf("empty reading")
+	}
+
+	var bpm float64
+	if strings.HasPrefix(text, "{") {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &fields); err != nil {
+			return 0, err
+		}
+
+		found := false
+		for _, key := range []string{"heart_rate", "heartRate", "bpm", "hr"} {
+			if value, ok := fields[key].(float64); ok {
+				bpm, found = value, true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("no heart rate in %s", text)
+		}
+	} else {
+		parts := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ',' || r == ';' || r == ' ' || r == '\t'
+		})
+		if len(parts) == 0 {
+			return 0, fmt.Errorf("empty reading")
+		}
+		value, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+		if err != nil {
+			return 0, err
+		}
+		bpm = value
+	}
+
+	if bpm < minHeartRate || bpm > maxHeartRate {
+		return 0, fmt.Errorf("heart rate %.0f out of range", bpm)
+	}
+	return bpm, nil
+}
+
+// latest holds the most recent reading of a source that receives data in the background
+type latest struct {
+	mu  sync.Mutex
+	bpm float64
+	at  time.Time
+}
+
+// set stores a reading taken at the given time
+func (l *latest) set(bpm float64, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.bpm, l.at = bpm, at
+}
+
+// HeartRate returns the latest reading if it is fresh
+func (l *latest) HeartRate() (float64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.at.IsZero() || time.Since(l.at) > staleAfter {
+		return 0, false
+	}
+	return l.bpm, true
+}
@@ -0,0 +1,91 @@
+package biometric
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	filePollInterval = 250 * time.Millisecond
+	fileTailSize     = 512 // Only the end of the file is read; the last line is what matters
+)
+
+// FileSource follows a file a bridge process rewrites or appends to with every reading.
+// The last non-empty line is the current heart rate; the file's modification time tells how fresh it is.
+type FileSource struct {
+	latest
+	path    string
+	done    chan struct{}
+	closing sync.Once
+}
+
+// OpenFile starts following the file
+func OpenFile(path string) (*FileSource, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	source := &FileSource{path: path, done: make(chan struct{})}
+	source.poll(time.Time{})
+	go source.run()
+	return source, nil
+}
+
+// Close stops following the file; closing it again does nothing
+func (s *FileSource) Close() error {
+	s.closing.Do(func() { close(s.done) })
+	return nil
+}
+
+// run rereads the file whenever it changes
+func (s *FileSource) run() {
+	ticker := time.NewTicker(filePollInterval)
+	defer ticker.Stop()
+
+	var modified time.Time
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			modified = s.poll(modified)
+		}
+	}
+}
+
+// poll reads the file if it changed since the given time and returns its modification time
+func (s *FileSource) poll(since time.Time) time.Time {
+	info, err := os.Stat(s.path)
+	if err != nil || !info.ModTime().After(since) {
+		return since
+	}
+
+	if bpm, err := readLastLine(s.path, info.Size()); err == nil {
+		s.set(bpm, info.ModTime())
+	}
+	return info.ModTime()
+}
+
+// readLastLine parses the last non-empty line of the file
+func readLastLine(path string, size int64) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	offset := max(0, size-fileTailSize)
+	tail := make([]byte, size-offset)
+	if _, err := file.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	tail = bytes.TrimRight(tail, "\r\n\t ")
+	if i := bytes.LastIndexByte(tail, '\n'); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return ParseReading(string(tail))
+}
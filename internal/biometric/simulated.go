@@ -0,0 +1,69 @@
+package biometric
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"nightmare/internal/common"
+)
+
+const (
+	defaultRestingRate = 70.0             // Resting heart rate of the simulated player
+	startleRecovery    = 20 * time.Second // Time for a startle to fade to a third
+	breathingPeriod    = 5 * time.Second  // The heart speeds up and slows down with breathing
+	breathingSwing     = 2.0              // Beats per minute gained on each breath
+	sensorNoise        = 1.0              // Beats per minute of random noise
+)
+
+// Simulated is a heart that beats at a resting rate, jumps when startled and calms down
+// over time. It runs on the game clock, so headless simulations can drive it.
+type Simulated struct {
+	Resting float64 // Resting heart rate
+
+	excess float64 // Beats per minute above resting from recent startles
+	last   time.Time
+	random *rand.Rand
+}
+
+// NewSimulated creates a simulated heart
+func NewSimulated(resting float64, seed int64) *Simulated {
+	return &Simulated{
+		Resting: resting,
+		last:    common.Now(),
+		random:  rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Startle raises the heart rate by the given number of beats per minute
+func (s *Simulated) Startle(bpm float64) {
+	s.advance()
+	s.excess = math.Min(s.excess+bpm, maxHeartRate-s.Resting-breathingSwing-sensorNoise)
+}
+
+// HeartRate returns the current simulated heart rate; it is always available
+func (s *Simulated) HeartRate() (float64, bool) {
+	s.advance()
+
+	phase := float64(s.last.UnixNano()%int64(breathingPeriod)) / float64(breathingPeriod)
+	breathing := math.Sin(phase*2*math.Pi) * breathingSwing
+	noise := (s.random.Float64()*2 - 1) * sensorNoise
+
+	return s.Resting + s.excess + breathing + noise, true
+}
+
+// Close does nothing; a simulated heart needs no cleanup
+func (s *Simulated) Close() error {
+	return nil
+}
+
+// advance lets the startle fade for the time passed since the previous call
+func (s *Simulated) advance() {
+	now := common.Now()
+	elapsed := now.Sub(s.last)
+	s.last = now
+
+	if elapsed > 0 {
+		s.excess *= math.Exp(-float64(elapsed) / float64(startleRecovery))
+	}
+}
@@ -0,0 +1,72 @@
+package biometric
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// UDPSource receives heart rate datagrams from a bridge on the same machine.
+// Every datagram is one reading in any format ParseReading accepts.
+type UDPSource struct {
+	latest
+	conn net.PacketConn
+}
+
+// ListenUDP listens on a loopback address; a bare port listens on 127.0.0.1.
+// Other addresses are refused so that playtest data never leaves the machine.
+func ListenUDP(address string) (*UDPSource, error) {
+	if !strings.Contains(address, ":") {
+		address = "127.0.0.1:" + address
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLoopback(host); err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	source := &UDPSource{conn: conn}
+	go source.run()
+	return source, nil
+}
+
+// checkLoopback accepts only hosts on this machine: heart rate is private and the bridge runs locally
+func checkLoopback(host string) error {
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return fmt.Errorf("heart rate must come from localhost, not %s", host)
+	}
+	return nil
+}
+
+// Addr returns the address the source listens on
+func (s *UDPSource) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Close stops listening
+func (s *UDPSource) Close() error {
+	return s.conn.Close()
+}
+
+// run reads datagrams until the connection is closed
+func (s *UDPSource) run() {
+	buffer := make([]byte, 1024)
+	for {
+		n, _, err := s.conn.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+		if bpm, err := ParseReading(string(buffer[:n])); err == nil {
+			s.set(bpm, time.Now())
+		}
+	}
+}
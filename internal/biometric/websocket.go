@@ -0,0 +1,274 @@
+package biometric
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	websocketGUID      = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11" // Fixed by RFC 6455
+	websocketMaxFrame  = 1 << 16                                // Heart rate messages are tiny
+	websocketRedial    = 2 * time.Second                        // Pause before reconnecting to a bridge that went away
+	websocketHandshake = 5 * time.Second
+)
+
+// WebSocket opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// WebSocketSource receives heart rate messages from a bridge serving a WebSocket.
+// Every message is one reading in any format ParseReading accepts. If the bridge
+// restarts, the source reconnects.
+type WebSocketSource struct {
+	latest
+	url *url.URL
+
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// DialWebSocket connects to a ws:// address on this machine; other hosts are refused.
+// Secure connections are not supported: the bridge runs locally.
+func DialWebSocket(address string) (*WebSocketSource, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ws" {
+		return nil, fmt.Errorf("unsupported scheme %q, only ws:// is supported", u.Scheme)
+	}
+	if err := checkLoopback(u.Hostname()); err != nil {
+		return nil, err
+	}
+	if u.Port() == "" {
+		u.Host = net.JoinHostPort(u.Hostname(), "80")
+	}
+
+	source := &WebSocketSource{url: u}
+	conn, reader, err := source.dial()
+	if err != nil {
+		return nil, err
+	}
+	go source.run(conn, reader)
+	return source, nil
+}
+
+// Close disconnects from the bridge
+func (s *WebSocketSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+// run reads messages and reconnects until the source is closed
+func (s *WebSocketSource) run(conn net.Conn, reader *bufio.Reader) {
+	for {
+		s.read(conn, reader)
+
+		for {
+			if s.isClosed() {
+				return
+			}
+			time.Sleep(websocketRedial)
+
+			var err error
+			if conn, reader, err = s.dial(); err == nil {
+				break
+			}
+		}
+	}
+}
+
+// isClosed checks whether Close was called
+func (s *WebSocketSource) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// dial opens the connection and performs the opening handshake
+func (s *WebSocketSource) dial() (net.Conn, *bufio.Reader, error) {
+	conn, err := net.DialTimeout("tcp", s.url.Host, websocketHandshake)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	request := &http.Request{
+		Method:     http.MethodGet,
+		URL:        s.url,
+		Host:       s.url.Host,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-WebSocket-Key":     {key},
+			"Sec-WebSocket-Version": {"13"},
+		},
+	}
+
+	conn.SetDeadline(time.Now().Add(websocketHandshake))
+	if err := request.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, nil, fmt.Errorf("websocket handshake with %s failed: %s", s.url, response.Status)
+	}
+	conn.SetDeadline(time.Time{})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		conn.Close()
+		return nil, nil, fmt.Errorf("source closed")
+	}
+	s.conn = conn
+	return conn, reader, nil
+}
+
+// read handles frames until the connection breaks or the bridge closes it
+func (s *WebSocketSource) read(conn net.Conn, reader *bufio.Reader) {
+	defer conn.Close()
+
+	var message []byte
+	for {
+		fin, opcode, payload, err := readFrame(reader)
+		if err != nil {
+			return
+		}
+
+		switch opcode {
+		case opText, opBinary, opContinuation:
+			message = append(message, payload...)
+			if len(message) > websocketMaxFrame {
+				return
+			}
+			if !fin {
+				continue
+			}
+			if bpm, err := ParseReading(string(message)); err == nil {
+				s.set(bpm, time.Now())
+			}
+			message = message[:0]
+
+		case opPing:
+			writeFrame(conn, opPong, payload)
+
+		case opClose:
+			writeFrame(conn, opClose, nil)
+			return
+		}
+	}
+}
+
+// acceptKey computes the answer a server gives to a handshake key
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// readFrame reads one frame
+func readFrame(reader *bufio.Reader) (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(reader, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err = io.ReadFull(reader, extended[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err = io.ReadFull(reader, extended[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if length > websocketMaxFrame {
+		err = fmt.Errorf("websocket frame of %d bytes is too large", length)
+		return
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(reader, mask[:]); err != nil {
+			return
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(reader, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
+
+// writeFrame writes a control frame; frames sent by a client must be masked
+func writeFrame(w io.Writer, opcode byte, payload []byte) error {
+	// Control frames carry at most 125 bytes
+	payload = payload[:min(len(payload), 125)]
+
+	frame := make([]byte, 0, 6+len(payload))
+	frame = append(frame, 0x80|opcode, 0x80|byte(len(payload)))
+
+	var mask [4]byte
+	rand.Read(mask[:])
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := w.Write(frame)
+	return err
+}
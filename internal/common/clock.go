@@ -0,0 +1,25 @@
+package common
+
+import "time"
+
+// clock returns the current game time
+var clock = time.Now
+
+// Now returns the current game time. It is the wall clock unless a headless
+// simulation replaced it to run faster than real time.
+func Now() time.Time {
+	return clock()
+}
+
+// Since returns the game time elapsed since t
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
+
+// SetClock replaces the game clock; nil restores the wall clock
+func SetClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	clock = now
+}
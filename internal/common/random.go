@@ -0,0 +1,20 @@
+package common
+
+import (
+	"math/rand"
+	"time"
+)
+
+// random is the source of the game's randomness
+var random = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// Rand returns the game's random number generator. It is seeded from the wall clock
+// unless a headless simulation seeded it to replay the same run.
+func Rand() *rand.Rand {
+	return random
+}
+
+// SeedRand reseeds the game's random number generator
+func SeedRand(seed int64) {
+	random = rand.New(rand.NewSource(seed))
+}
@@ -52,6 +52,26 @@ const (
 	EventWhisper
 )
 
+// String returns the name of the scare event type
+func (t ScareEventType) String() string {
+	switch t {
+	case EventAmbientSound:
+		return "AmbientSound"
+	case EventSuddenNoise:
+		return "SuddenNoise"
+	case EventCreatureAppearance:
+		return "CreatureAppearance"
+	case EventEnvironmentChange:
+		return "EnvironmentChange"
+	case EventHallucination:
+		return "Hallucination"
+	case EventWhisper:
+		return "Whisper"
+	default:
+		return "Unknown"
+	}
+}
+
 // TileType represents a tile type in the world
 type TileType int
 
@@ -88,6 +108,7 @@ type WorldObject struct {
 	Position    Vector2D
 	Solid       bool
 	Interactive bool
+	Name        string // What the object is, e.g. the name of a dropped item
 }
 
 // PlayerAction represents a player action record
@@ -105,10 +126,28 @@ type ScareEvent struct {
 	Intensity    float64 // From 0 to 1
 	Position     Vector2D
 	Duration     time.Duration
-	CreatureType string // Type of creature if the event is related to a creature
+	CreatureType string     // Type of creature if the event is related to a creature
+	Path         []Vector2D // Route for creatures that follow a path (doppelganger)
 	Timestamp    time.Time
 }
 
+// Bounds is an axis-aligned rectangle of the world: Min lies inside it, Max just outside
+type Bounds struct {
+	Min, Max Vector2D
+}
+
+// Contains checks whether the point lies within the bounds
+func (b Bounds) Contains(p Vector2D) bool {
+	return p.X >= b.Min.X && p.Y >= b.Min.Y && p.X < b.Max.X && p.Y < b.Max.Y
+}
+
+// Clamp moves the point to the nearest position within the bounds
+func (b Bounds) Clamp(p Vector2D) Vector2D {
+	p.X = math.Max(b.Min.X, math.Min(p.X, b.Max.X-1))
+	p.Y = math.Max(b.Min.Y, math.Min(p.Y, b.Max.Y-1))
+	return p
+}
+
 // Distance calculates the distance between two points
 func Distance(a, b Vector2D) float64 {
 	dx := a.X - b.X
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"nightmare/internal/ai"
+	"nightmare/internal/biometric"
+	"nightmare/internal/event"
+)
+
+// scareStartle - на сколько ударов в минуту испуг полной силы разгоняет смоделированное сердце
+const scareStartle = 35.0
+
+// startler - источник пульса, которому можно сообщить об испуге, например смоделированное сердце
+type startler interface {
+	Startle(bpm float64)
+}
+
+// SetBiometricSource подключает датчик пульса игрока: директор учитывает пульс в темпе,
+// наблюдатель - в оценке страха, а звук сердцебиения бьется в такт настоящему сердцу
+func (g *Game) SetBiometricSource(source biometric.BiometricSource) {
+	g.heart = ai.NewHeartMonitor(source)
+	g.heartSource = source
+	g.attachHeartMonitor()
+}
+
+// attachHeartMonitor подключает пульс к текущим директору и наблюдателю
+func (g *Game) attachHeartMonitor() {
+	if g.heart != nil {
+		g.director.SetHeartMonitor(g.heart)
+		g.observer.SetHeartMonitor(g.heart)
+	}
+
+	// Смоделированное сердце не слышит игру само, поэтому о каждом испуге ему сообщают
+	if heart, ok := g.heartSource.(startler); ok {
+		g.events.AddListener(event.EventScareTriggered, func(data event.EventData) {
+			if intensity, ok := data.Value.(float64); ok {
+				heart.Startle(intensity * scareStartle)
+			}
+		})
+	}
+}
+
+// updateHeartRate снимает показания пульса и передает их звуку сердцебиения
+func (g *Game) updateHeartRate() {
+	if g.heart == nil {
+		return
+	}
+
+	g.heart.Update()
+
+	// Без сигнала сердцебиение молчит, а не застывает на последнем значении
+	rate, ok := g.heart.Rate()
+	if !ok {
+		rate = 0
+	}
+	g.sounds.SetHeartbeatRate(rate)
+}
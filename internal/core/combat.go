@@ -0,0 +1,99 @@
+package core
+
+import (
+	"math"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+	"nightmare/internal/event"
+	"nightmare/internal/item"
+	"nightmare/internal/render"
+	"nightmare/internal/sound"
+)
+
+// Параметры ближнего боя по умолчанию (если у оружия нет соответствующих характеристик)
+const (
+	defaultWeaponReach     = 1.8         // Дальность удара в тайлах
+	defaultWeaponArc       = math.Pi / 2 // Ширина дуги удара
+	defaultWeaponKnockback = 1.0         // Сила отброса в тайлах
+	defaultWeaponCooldown  = 30          // Кадров между ударами
+	weaponDurabilityCost   = 1.0         // Износ оружия за удар, попавший в цель
+	staggerFrames          = 20          // На сколько кадров удар оглушает существо
+)
+
+// weaponStat возвращает характеристику оружия или значение по умолчанию
+func weaponStat(weapon *item.Item, name string, fallback float64) float64 {
+	if value, ok := weapon.Stats[name]; ok {
+		return value
+	}
+	return fallback
+}
+
+// playerAttack наносит удар экипированным оружием по дуге перед игроком
+func (g *Game) playerAttack() {
+	if g.attackCooldown > 0 {
+		return
+	}
+
+	weapon := g.inventory.GetEquippedItem()
+	if weapon == nil || weapon.Type != item.ItemWeapon || weapon.Durability <= 0 {
+		return
+	}
+
+	g.attackCooldown = int(weaponStat(weapon, "cooldown", defaultWeaponCooldown))
+	g.player.Attack()
+
+	origin := g.player.Position.ToCommonVector()
+	g.sounds.PlaySoundAt(sound.SoundSwing, soundPosition(origin), 1, 10)
+
+	damage := weaponStat(weapon, "damage", 0)
+	reach := weaponStat(weapon, "range", defaultWeaponReach)
+	arc := weaponStat(weapon, "arc", defaultWeaponArc)
+	knockback := weaponStat(weapon, "knockback", defaultWeaponKnockback)
+
+	targets := g.world.CreaturesInArc(origin, g.player.Direction, arc, reach)
+	if len(targets) == 0 {
+		return
+	}
+
+	for _, target := range targets {
+		creature := target.Creature
+		creature.TakeDamage(damage)
+
+		// Отбрасываем существо от игрока и ненадолго оглушаем
+		creature.Knockback(math.Atan2(target.Position.Y-origin.Y, target.Position.X-origin.X), knockback)
+		creature.Stagger(staggerFrames)
+		target.Position = creature.Position.ToCommonVector()
+
+		g.sounds.PlaySoundAt(sound.SoundImpact, soundPosition(target.Position), 1, 20)
+
+		if creature.IsDead() {
+			g.events.TriggerWithData(event.NewCreatureKilledEvent(creature, g.player, target.Position))
+			g.effects.AddEffect(render.EffectPulse, 0.5, 0.4)
+		}
+	}
+
+	// Обратная связь попадания
+	g.effects.AddEffect(render.EffectFlash, 0.2, 0.1)
+	g.effects.AddEffect(render.EffectChromaticAberration, 0.3, 0.2)
+
+	// Оружие изнашивается
+	weapon.Durability = math.Max(0, weapon.Durability-weaponDurabilityCost)
+}
+
+// soundPosition переводит позицию в мире в координаты пространственного звука
+func soundPosition(position common.Vector2D) sound.Vector3D {
+	return sound.Vector3D{X: position.X, Y: position.Y, Z: 0}
+}
+
+// newStartingInventory создает инвентарь игрока с начальным снаряжением
+func newStartingInventory(player *entity.Player, events *event.EventManager, factory *item.ItemFactory) *item.Inventory {
+	inventory := item.NewInventory(player, events)
+
+	if weapon := factory.CreateItem("weapon_pipe"); weapon != nil {
+		inventory.AddItem(weapon)
+		inventory.EquipItem(weapon)
+	}
+
+	return inventory
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"nightmare/internal/ai"
+	"nightmare/internal/common"
+)
+
+const (
+	debugProfileLines = 3 // Сколько самых сильных страхов и реакций показывать
+	debugSignalCount  = 4 // Сколько последних микроповедений показывать
+)
+
+// Цвета графиков напряжения и настроения
+var (
+	tensionColor = color.RGBA{255, 80, 80, 255}
+	moodColor    = color.RGBA{80, 160, 255, 255}
+)
+
+// SetDecisionLog записывает журнал решений директора в файл
+func (g *Game) SetDecisionLog(path string) error {
+	file, err := ai.OpenDecisionLog(path)
+	if err != nil {
+		return err
+	}
+
+	g.CloseDecisionLog()
+	g.decisionLog = file
+	g.attachDecisionLog()
+	return nil
+}
+
+// CloseDecisionLog закрывает файл журнала решений
+func (g *Game) CloseDecisionLog() {
+	if g.decisionLog == nil {
+		return
+	}
+	g.decisionLog.Close()
+	g.decisionLog = nil
+	g.director.SetDecisionOutput(nil)
+}
+
+// attachDecisionLog подключает файл журнала к текущему директору
+func (g *Game) attachDecisionLog() {
+	if g.decisionLog != nil {
+		g.director.SetDecisionOutput(g.decisionLog)
+	}
+}
+
+// drawDebug отрисовывает отладочную панель директора: графики, состояние и журнал решений
+func (g *Game) drawDebug(screen *ebiten.Image) {
+	state := g.director.DebugState()
+
+	tension := make([]float64, len(state.History))
+	mood := make([]float64, len(state.History))
+	for i, sample := range state.History {
+		tension[i] = sample.Tension
+		mood[i] = sample.Mood
+	}
+	g.renderer.DrawDebugGraph(screen, [][]float64{tension, mood}, []color.RGBA{tensionColor, moodColor})
+
+	g.renderer.DrawDebugInfo(screen, debugLines(state))
+
+	entries := make([]string, len(state.Decisions))
+	for i, decision := range state.Decisions {
+		entries[i] = decision.String()
+	}
+	g.renderer.DrawDebugLog(screen, entries)
+}
+
+// debugLines собирает отладочную информацию о решениях директора
+func debugLines(state ai.DebugState) []string {
+	pacing := state.Pacing
+	lines := []string{
+		"Director: " + state.Personality,
+		fmt.Sprintf("Phase: %s (%.0fs) Quiet: %.0fs", pacing.Phase, pacing.PhaseTime.Seconds(), pacing.QuietRemaining.Seconds()),
+		fmt.Sprintf("Stress %.2f Tension %.2f Mood %.2f", pacing.Stress, pacing.Tension, pacing.Mood),
+		"Context: " + state.Context,
+	}
+	if state.HeartRate > 0 {
+		lines = append(lines, fmt.Sprintf("Heart: %.0f bpm (resting %.0f)", state.HeartRate, state.RestingRate))
+	}
+	if state.Sequence != "" {
+		lines = append(lines, "Sequence: "+state.Sequence)
+	}
+	if len(state.Avoided) > 0 {
+		rules := make([]string, 0, len(state.Avoided))
+		for rule, count := range state.Avoided {
+			rules = append(rules, fmt.Sprintf("%s %d", rule, count))
+		}
+		sort.Strings(rules)
+		lines = append(lines, "Avoided: "+strings.Join(rules, ", "))
+	}
+
+	if len(state.Signals) > 0 {
+		lines = append(lines, "Signals:")
+		for _, signal := range state.Signals[max(0, len(state.Signals)-debugSignalCount):] {
+			lines = append(lines, "  "+signal.String())
+		}
+	}
+
+	lines = append(lines, "Effectiveness:")
+	for eventType := common.EventAmbientSound; eventType <= common.EventWhisper; eventType++ {
+		value, ok := state.Effectiveness[eventType]
+		if !ok {
+			lines = append(lines, fmt.Sprintf("  %s: -", eventType))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %.2f", eventType, value))
+	}
+
+	lines = append(lines, "Fears:")
+	for _, fearType := range topKeys(state.FearProfile) {
+		lines = append(lines, fmt.Sprintf("  %s: %.2f", fearType, state.FearProfile[fearType]))
+	}
+
+	lines = append(lines, "Reactions:")
+	for _, reactorType := range topKeys(state.ReactorProfile) {
+		lines = append(lines, fmt.Sprintf("  %s: %.2f", reactorType, state.ReactorProfile[reactorType]))
+	}
+
+	lines = append(lines, "Bandit:")
+	for _, arm := range state.Bandit[:min(len(state.Bandit), debugProfileLines)] {
+		lines = append(lines, fmt.Sprintf("  %s: %.2f (%.0f pulls)", arm.Arm, arm.Mean, arm.Pulls))
+	}
+
+	lines = append(lines, "Recommended:")
+	for _, recommendation := range state.Recommendations {
+		lines = append(lines, fmt.Sprintf("  %s %.2f (%s)", recommendation.ScareType, recommendation.Priority, recommendation.FearTarget))
+	}
+
+	return lines
+}
+
+// topKeys возвращает ключи профиля с наибольшими значениями
+func topKeys[K ~int](profile map[K]float64) []K {
+	keys := make([]K, 0, len(profile))
+	for key := range profile {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if profile[keys[i]] != profile[keys[j]] {
+			return profile[keys[i]] > profile[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys[:min(len(keys), debugProfileLines)]
+}
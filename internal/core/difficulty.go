@@ -0,0 +1,15 @@
+package core
+
+import "nightmare/internal/ai"
+
+// SetDifficulty задает сложность: по ней директор выбирает кривую темпа.
+// Сложность сохраняется и после перезапуска игры.
+func (g *Game) SetDifficulty(difficulty ai.Difficulty) {
+	g.difficulty = difficulty
+	g.attachDifficulty()
+}
+
+// attachDifficulty передает сложность текущему директору
+func (g *Game) attachDifficulty() {
+	g.director.SetDifficulty(g.difficulty)
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"nightmare/internal/entity"
+	"nightmare/internal/event"
+	"nightmare/internal/item"
+	"nightmare/internal/world"
+)
+
+// SetEndlessForest включает режим бесконечного кошмарного леса: мир подгружается чанками
+// вокруг игрока, а измененные чанки сохраняются в каталог dir (пустая строка - только
+// на время игры). Лес из каталога продолжается с тем же зерном; если леса там нет, он создается.
+func (g *Game) SetEndlessForest(dir string) error {
+	store := world.NewChunkStore(dir)
+
+	seed, err := store.LoadSeed()
+	if errors.Is(err, os.ErrNotExist) {
+		seed = time.Now().UnixNano()
+		err = store.SaveSeed(seed)
+	}
+	if err != nil {
+		return err
+	}
+
+	g.forest = store
+	g.forestSeed = seed
+	g.resetGame()
+	return nil
+}
+
+// SaveForest сохраняет измененные чанки бесконечного леса
+func (g *Game) SaveForest() {
+	if err := g.world.SaveChunks(); err != nil {
+		log.Printf("Не удалось сохранить лес: %v", err)
+	}
+}
+
+// newWorld создает бесконечный лес, если он выбран, иначе мир фиксированного размера
+func (g *Game) newWorld() (*world.World, error) {
+	if g.forest != nil {
+		return world.NewEndlessWorld(g.forestSeed, g.forest)
+	}
+	return world.NewWorld(256, 256)
+}
+
+// dropOnGround оставляет выброшенные из инвентаря предметы лежать в мире
+func dropOnGround(w *world.World, events *event.EventManager) {
+	events.AddCustomListener("item_dropped", func(data event.EventData) {
+		dropped, ok := data.Target.(*item.Item)
+		if !ok {
+			return
+		}
+		if position, ok := data.Position.(entity.Vector2D); ok {
+			w.DropItem(dropped.Name, position.ToCommonVector())
+		}
+	})
+}
@@ -1,12 +1,21 @@
 package core
 
 import (
+	"image/color"
+	"math"
+	"os"
+	"time"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 
 	"nightmare/internal/ai"
+	"nightmare/internal/biometric"
 	"nightmare/internal/entity"
+	"nightmare/internal/event"
+	"nightmare/internal/item"
 	"nightmare/internal/render"
+	"nightmare/internal/sound"
 	"nightmare/internal/world"
 )
 
@@ -27,11 +36,44 @@ type Game struct {
 	world      *world.World
 	renderer   *render.Renderer
 	director   *ai.Director
+	observer   *ai.ObserverSystem
+	behavior   *ai.BehaviorDetector
+	lighting   *render.LightingSystem
+	flashlight *render.Light
+	events     *event.EventManager
+	inventory  *item.Inventory
+	items      *item.ItemFactory
+	sounds     *sound.SoundManager
+	effects    *render.EffectManager
 	frameCount int
+
+	attackCooldown int                       // Кадров до следующего удара
+	showDebug      bool                      // Показывать отладочную информацию (F3)
+	decisionLog    *os.File                  // Файл журнала решений директора
+	heart          *ai.HeartMonitor          // Пульс игрока, если подключен датчик
+	heartSource    biometric.BiometricSource // Датчик, с которого снимается пульс
+	personality    string                    // Имя личности директора или RandomPersonality
+	difficulty     ai.Difficulty             // Сложность, по которой директор выбирает темп
+
+	forest     *world.ChunkStore // Хранилище бесконечного леса; nil - мир фиксированного размера
+	forestSeed int64             // Зерно, из которого растет бесконечный лес
+
+	profilePath string        // Где хранится профиль игрока; пустой путь - не сохранять
+	scares      []activeScare // Длящиеся пугающие события
+	baseAmbient color.RGBA    // Фоновый свет без пугающих событий
+
+	flickerUntil time.Time // До какого момента мигает свет по воле сцены испуга
+	silenceUntil time.Time // До какого момента сцена испуга держит тишину
+
+	// Состояние игрока на прошлом кадре, для событий наблюдателя
+	lastPosition entity.Vector2D
+	lastSanity   float64
+	lastHealth   float64
 }
 
-// NewGame создает новую игру
-func NewGame() (*Game, error) {
+// NewGame создает новую игру. Профиль игрока загружается из profilePath и сохраняется туда же;
+// при пустом пути директор каждый раз начинает с чистого листа.
+func NewGame(profilePath string) (*Game, error) {
 	// Создаем игрока
 	player := entity.NewPlayer()
 
@@ -47,17 +89,124 @@ func NewGame() (*Game, error) {
 		return nil, err
 	}
 
-	// Создаем ИИ-директора
-	director := ai.NewDirector(player, world)
+	// Игрок появляется там, где мир его ждет; существа реагируют на него
+	player.Position = entity.FromCommonVector(world.SpawnPoint())
+	world.SetPlayer(player)
+
+	// Освещение нужно и для игровой логики (тени, безликие)
+	lighting, flashlight := newLighting(world, player)
 
-	return &Game{
+	// Системы событий, звука и экранных эффектов
+	events := event.NewEventManager()
+	sounds := sound.NewSoundManager()
+	sounds.LoadAllSounds()
+
+	// Создаем ИИ-директора и систему наблюдения за игроком
+	director, observer, behavior := newDirector(player, world, events)
+
+	// Предметы создаются по шаблонам: и начальное снаряжение, и подобранное с земли
+	items := item.NewItemFactory()
+	items.CreateItemsDatabase()
+
+	game := &Game{
 		state:      StateMainMenu,
 		player:     player,
 		world:      world,
 		renderer:   renderer,
 		director:   director,
+		observer:   observer,
+		behavior:   behavior,
+		lighting:   lighting,
+		flashlight: flashlight,
+		events:     events,
+		inventory:  newStartingInventory(player, events, items),
+		items:      items,
+		sounds:     sounds,
+		effects:    render.NewEffectManager(),
 		frameCount: 0,
-	}, nil
+
+		lastPosition: player.Position,
+		lastSanity:   player.Sanity,
+		lastHealth:   player.Health,
+
+		difficulty:  ai.DifficultyNormal,
+		profilePath: profilePath,
+		baseAmbient: lighting.AmbientLight(),
+	}
+
+	// Директор проигрывает звуки, эффекты и галлюцинации через игру
+	director.SetPresenter(game)
+
+	// Выброшенные предметы остаются лежать на земле
+	dropOnGround(world, events)
+
+	// Кошмар вернувшегося игрока начинается с того, что пугало его в прошлый раз
+	game.loadProfile()
+
+	return game, nil
+}
+
+// newDirector создает ИИ-директора вместе с анализатором, наблюдателем
+// и детектором микроповедения, выводы которых управляют испугами
+func newDirector(player *entity.Player, w *world.World, events *event.EventManager) (*ai.Director, *ai.ObserverSystem, *ai.BehaviorDetector) {
+	analyzer := ai.NewAnalyzer(player)
+	analyzer.SetWorldArea(w.MapArea())
+
+	director := ai.NewDirector(player, w)
+	director.SetAnalyzer(analyzer)
+	director.SetEventManager(events)
+
+	observer := ai.NewObserverSystem(player, events, analyzer, director)
+	observer.Initialize()
+	director.SetObserver(observer)
+
+	// Замирания, рывки и метания игрока распознаются по его движению в каждом кадре
+	behavior := ai.NewBehaviorDetector(player, w)
+	director.SetBehaviorDetector(behavior)
+
+	// Авторские сцены испуга идут вперемешку с процедурными
+	loadSequences(director, events)
+
+	return director, observer, behavior
+}
+
+// publishPlayerEvents сообщает наблюдателю о перемещении, уроне и потере рассудка игрока
+func (g *Game) publishPlayerEvents() {
+	if g.player.Position != g.lastPosition {
+		g.events.TriggerWithData(event.NewPlayerMovedEvent(g.player, g.lastPosition, g.player.Position))
+		g.lastPosition = g.player.Position
+	}
+
+	if g.player.Health < g.lastHealth {
+		g.events.TriggerWithData(event.NewPlayerDamagedEvent(g.player, g.world, g.lastHealth-g.player.Health))
+	}
+	g.lastHealth = g.player.Health
+
+	if g.player.Sanity != g.lastSanity {
+		g.events.TriggerWithData(event.NewPlayerSanityChangedEvent(g.player, g.lastSanity, g.player.Sanity))
+		g.lastSanity = g.player.Sanity
+	}
+}
+
+// newLighting создает систему освещения с фонариком игрока и подключает ее к миру
+func newLighting(w *world.World, player *entity.Player) (*render.LightingSystem, *render.Light) {
+	lighting := render.NewLightingSystem(800, 600, w.Collision())
+
+	flashlight := lighting.CreateFlashlight(player.Position)
+	lighting.AddLight(flashlight)
+
+	w.SetLightSampler(lighting)
+
+	return lighting, flashlight
+}
+
+// updateFlashlight перемещает луч фонарика вслед за игроком
+func (g *Game) updateFlashlight() {
+	g.flashlight.Position = g.player.Position
+	g.flashlight.Direction = entity.Vector2D{
+		X: math.Cos(g.player.Direction),
+		Y: math.Sin(g.player.Direction),
+	}
 }
 
 // Update обновляет состояние игры
@@ -69,6 +218,9 @@ func (g *Game) Update() error {
 		// Обработка ввода в главном меню
 		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
 			g.state = StatePlaying
+
+			// После загрузки или возрождения игрок получает передышку без существ
+			g.director.StartGrace()
 		}
 
 	case StatePlaying:
@@ -80,6 +232,22 @@ func (g *Game) Update() error {
 
 		// Обновление игрока
 		g.player.Update()
+		g.updateFlashlight()
+		if g.attackCooldown > 0 {
+			g.attackCooldown--
+		}
+
+		// Обработка событий, звуков и эффектов
+		g.updateScares()
+		g.updateHallucinationSounds()
+		g.publishPlayerEvents()
+		g.events.ProcessEvents()
+		g.updateHeartRate()
+		g.behavior.Update()
+		g.observer.Update()
+		g.sounds.SetListenerPosition(soundPosition(g.player.Position.ToCommonVector()))
+		g.sounds.Update()
+		g.effects.Update(1.0 / 60.0)
 
 		// Обновление ИИ-директора каждые 30 кадров (примерно 0.5 сек)
 		if g.frameCount%30 == 0 {
@@ -87,9 +255,24 @@ func (g *Game) Update() error {
 			g.director.AdjustWorld()
 		}
 
+		// Сцены испуга ведутся каждый кадр, чтобы шаги шли точно по сценарию
+		g.director.UpdateSequences()
+
+		// Директор гарантирует тихие окна, звук и свет их соблюдают
+		quiet := g.director.InQuietWindow()
+		g.sounds.SetQuiet(quiet || g.silenced())
+		g.lighting.SetQuiet(quiet)
+		g.lighting.Update(1.0 / 60.0)
+
+		// Профиль периодически сохраняется, чтобы не потерять его при сбое
+		if g.frameCount%profileSaveInterval == 0 {
+			g.SaveProfile()
+		}
+
 		// Проверка условий окончания игры
 		if g.player.Health <= 0 || g.player.Sanity <= 0 {
 			g.state = StateGameOver
+			g.SaveProfile()
 		}
 
 	case StatePaused:
@@ -121,12 +304,19 @@ func (g *Game) Draw(screen *ebiten.Image) {
 
 		// Отрисовка существ
 		g.renderer.DrawEntities(screen, g.world.Entities, g.player)
+		g.renderer.DrawHallucinations(screen, g.world.Perception().Hallucinations())
 
 		// Отрисовка игрока
 		g.renderer.DrawPlayer(screen, g.player)
 
+		// Экранные эффекты
+		g.effects.Apply(screen)
+
 		// Отрисовка UI
 		g.renderer.DrawUI(screen, g.player)
+		if g.showDebug {
+			g.drawDebug(screen)
+		}
 
 		if g.state == StatePaused {
 			g.renderer.DrawPauseMenu(screen)
@@ -161,6 +351,22 @@ func (g *Game) handlePlayerInput() {
 	// Взаимодействие
 	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
 		g.player.Interact(g.world)
+		g.pickUp()
+	}
+
+	// Атака
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		g.playerAttack()
+	}
+
+	// Выбросить предмет из рук
+	if inpututil.IsKeyJustPressed(ebiten.KeyQ) && g.inventory.EquippedItem != nil {
+		g.inventory.DropItem(g.inventory.EquippedItem, 1)
+	}
+
+	// Отладочная информация
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		g.showDebug = !g.showDebug
 	}
 
 	// Пауза
@@ -175,15 +381,42 @@ func (g *Game) handlePlayerInput() {
 
 // resetGame сбрасывает игру
 func (g *Game) resetGame() {
+	// Изменения леса переживают гибель игрока
+	g.SaveForest()
+
 	g.player = entity.NewPlayer()
 
 	var err error
-	g.world, err = world.NewWorld(256, 256)
+	g.world, err = g.newWorld()
 	if err != nil {
 		panic(err) // В реальной игре нужно обработать ошибку более изящно
 	}
 
-	g.director = ai.NewDirector(g.player, g.world)
+	g.player.Position = entity.FromCommonVector(g.world.SpawnPoint())
+	g.world.SetPlayer(g.player)
+	g.lighting, g.flashlight = newLighting(g.world, g.player)
+
+	// Звуковой менеджер не пересоздаем: аудиоконтекст может быть только один
+	g.sounds.StopAllSounds()
+	g.events = event.NewEventManager()
+	g.director, g.observer, g.behavior = newDirector(g.player, g.world, g.events)
+	g.director.SetPresenter(g)
+	g.attachDecisionLog()
+	g.attachHeartMonitor()
+	g.attachDifficulty()
+	g.attachPersonality()
+	g.loadProfile()
+	g.scares = nil
+	g.flickerUntil = time.Time{}
+	g.silenceUntil = time.Time{}
+	g.baseAmbient = g.lighting.AmbientLight()
+	g.lastPosition = g.player.Position
+	g.lastSanity = g.player.Sanity
+	g.lastHealth = g.player.Health
+	g.inventory = newStartingInventory(g.player, g.events, g.items)
+	dropOnGround(g.world, g.events)
+	g.effects = render.NewEffectManager()
+	g.attackCooldown = 0
 	g.state = StateMainMenu
 	g.frameCount = 0
 }
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"math/rand"
+	"time"
+
+	"nightmare/internal/ai"
+)
+
+// RandomPersonality - имя, по которому личность директора выбирается заново в каждой игре
+const RandomPersonality = "random"
+
+// SetPersonality задает личность директора по имени; RandomPersonality - новая случайная
+// личность в каждой игре. Возвращает false, если такой личности нет.
+func (g *Game) SetPersonality(name string) bool {
+	if name != RandomPersonality {
+		if _, ok := ai.FindPersonality(name); !ok {
+			return false
+		}
+	}
+
+	g.personality = name
+	g.attachPersonality()
+	return true
+}
+
+// attachPersonality передает личность текущему директору
+func (g *Game) attachPersonality() {
+	switch g.personality {
+	case "":
+		// Без выбора директор остается уравновешенным
+	case RandomPersonality:
+		g.director.SetPersonality(ai.RandomPersonality(rand.New(rand.NewSource(time.Now().UnixNano()))))
+	default:
+		personality, _ := ai.FindPersonality(g.personality)
+		g.director.SetPersonality(personality)
+	}
+}
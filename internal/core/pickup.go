@@ -0,0 +1,27 @@
+package core
+
+// pickupReach - на каком расстоянии в тайлах игрок дотягивается до предмета на земле
+const pickupReach = 1.5
+
+// pickUp подбирает ближайший предмет, лежащий на земле рядом с игроком.
+// Предмет остается лежать, если его нет среди шаблонов или он не помещается в инвентарь.
+func (g *Game) pickUp() {
+	g.world.PickUpItem(g.player.Position.ToCommonVector(), pickupReach, func(name string) bool {
+		picked := g.items.CreateItemByName(name)
+		if picked == nil {
+			return false
+		}
+
+		// На земле каждый предмет лежит по одному
+		picked.Quantity = 1
+		if !g.inventory.AddItem(picked) {
+			return false
+		}
+
+		// Выброшенное оружие, подобранное снова, возвращается в пустые руки
+		if picked.Equippable && g.inventory.EquippedItem == nil {
+			g.inventory.EquipItem(picked)
+		}
+		return true
+	})
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"nightmare/internal/ai"
+	"nightmare/internal/common"
+)
+
+// profileSaveInterval - как часто сохраняется профиль игрока (в кадрах)
+const profileSaveInterval = 60 * 60
+
+// loadProfile загружает профиль игрока и передает его директору
+func (g *Game) loadProfile() {
+	if g.profilePath == "" {
+		return
+	}
+
+	profile, err := ai.LoadPlayerProfile(g.profilePath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Не удалось загрузить профиль игрока: %v", err)
+		}
+		return
+	}
+
+	// Давние впечатления забываются
+	profile.Decay(common.Now())
+	g.director.ApplyProfile(profile)
+}
+
+// SaveProfile сохраняет то, что директор узнал об игроке
+func (g *Game) SaveProfile() {
+	if g.profilePath == "" {
+		return
+	}
+
+	if err := g.director.Profile().Save(g.profilePath); err != nil {
+		log.Printf("Не удалось сохранить профиль игрока: %v", err)
+	}
+}
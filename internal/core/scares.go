@@ -0,0 +1,148 @@
+package core
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	"time"
+
+	"nightmare/internal/common"
+	"nightmare/internal/render"
+	"nightmare/internal/sound"
+	"nightmare/internal/world"
+)
+
+// activeScare - пугающее событие, которое еще длится
+type activeScare struct {
+	event   common.ScareEvent
+	endsAt  time.Time
+	soundID int // Звук события, останавливается по окончании
+}
+
+// Звуки для пугающих событий
+var (
+	ambientScareSounds = []sound.SoundID{sound.SoundRustling, sound.SoundCreak, sound.SoundDripping, sound.SoundChimes, sound.SoundDistantHowl}
+	suddenNoiseSounds  = []sound.SoundID{sound.SoundDoor, sound.SoundThunder, sound.SoundGrowl, sound.SoundScream} // От тихих к громким
+)
+
+// PlayAmbientScare воспроизводит далекий звук и сгущает темноту вокруг игрока
+func (g *Game) PlayAmbientScare(event common.ScareEvent) {
+	id := ambientScareSounds[rand.Intn(len(ambientScareSounds))]
+	soundID := g.sounds.PlaySoundAt(id, soundPosition(event.Position), 5, 50)
+
+	seconds := event.Duration.Seconds()
+	g.effects.AddEffect(render.EffectFog, 0.2+event.Intensity*0.3, seconds)
+
+	// Фоновый свет тускнеет, пока звучит звук
+	g.lighting.SetAmbientLight(scaleColor(g.baseAmbient, 1-event.Intensity*0.5))
+
+	g.addScare(event, soundID)
+}
+
+// PlaySuddenNoise воспроизводит резкий звук за спиной игрока, от которого мигает фонарь
+func (g *Game) PlaySuddenNoise(event common.ScareEvent) {
+	index := int(event.Intensity * float64(len(suddenNoiseSounds)-1))
+	index = max(0, min(index, len(suddenNoiseSounds)-1))
+	soundID := g.sounds.PlaySoundAt(suddenNoiseSounds[index], soundPosition(event.Position), 1, 30)
+
+	seconds := event.Duration.Seconds()
+	g.effects.AddEffect(render.EffectFlash, event.Intensity*0.5, math.Min(seconds, 0.3))
+	g.effects.AddEffect(render.EffectPulse, event.Intensity, seconds)
+	g.effects.AddEffect(render.EffectChromaticAberration, event.Intensity*0.4, seconds)
+
+	g.addScare(event, soundID)
+}
+
+// ShowHallucination показывает существо, которого на самом деле нет
+func (g *Game) ShowHallucination(event common.ScareEvent) {
+	frames := int(event.Duration.Seconds() * 60)
+	g.world.SpawnHallucination(event.CreatureType, event.Position, frames)
+
+	soundID := g.sounds.PlaySoundAt(sound.SoundLaughter, soundPosition(event.Position), 2, 15)
+
+	seconds := event.Duration.Seconds()
+	g.effects.AddEffect(render.EffectDistortion, event.Intensity*0.5, seconds)
+	g.effects.AddEffect(render.EffectChromaticAberration, event.Intensity*0.4, seconds)
+
+	g.addScare(event, soundID)
+}
+
+// PlayWhisper воспроизводит шепот у самого уха игрока
+func (g *Game) PlayWhisper(event common.ScareEvent) {
+	soundID := g.sounds.PlaySoundAt(sound.SoundWhisper, soundPosition(event.Position), 0.5, 6)
+
+	seconds := event.Duration.Seconds()
+	g.effects.AddEffect(render.EffectVignette, 0.5+event.Intensity*0.5, seconds)
+	g.effects.AddEffect(render.EffectBlur, event.Intensity*0.3, seconds)
+
+	g.addScare(event, soundID)
+}
+
+// Звуки, которыми выдают себя галлюцинации
+var hallucinationSounds = map[world.HallucinationKind]sound.SoundID{
+	world.HallucinationCreature: sound.SoundGrowl,
+	world.HallucinationObject:   sound.SoundChimes,
+	world.HallucinationLight:    sound.SoundStaticNoise,
+	world.HallucinationPath:     sound.SoundFootstep,
+}
+
+// updateHallucinationSounds озвучивает только что появившиеся галлюцинации
+func (g *Game) updateHallucinationSounds() {
+	for _, h := range g.world.Perception().Hallucinations() {
+		if h.Age != 1 {
+			continue
+		}
+		g.sounds.PlaySoundAt(hallucinationSounds[h.Kind], soundPosition(h.Position), 2, 20)
+	}
+}
+
+// addScare запоминает событие, чтобы завершить его по истечении длительности
+func (g *Game) addScare(event common.ScareEvent, soundID int) {
+	g.scares = append(g.scares, activeScare{
+		event:   event,
+		endsAt:  time.Now().Add(event.Duration),
+		soundID: soundID,
+	})
+}
+
+// updateScares ведет длящиеся пугающие события и убирает их последствия по окончании
+func (g *Game) updateScares() {
+	now := time.Now()
+	dimmed := false
+	flickering := false
+
+	active := g.scares[:0]
+	for _, scare := range g.scares {
+		if now.After(scare.endsAt) {
+			g.sounds.StopSound(scare.soundID)
+			continue
+		}
+
+		switch scare.event.Type {
+		case common.EventAmbientSound:
+			dimmed = true
+		case common.EventSuddenNoise:
+			flickering = true
+		}
+		active = append(active, scare)
+	}
+	g.scares = active
+
+	// Фонарь мигает, пока не стихнет резкий звук или пока этого требует сцена испуга
+	flickering = flickering || g.flickering()
+	g.flashlight.IsActive = !flickering || rand.Float64() > 0.35
+
+	if !dimmed {
+		g.lighting.SetAmbientLight(g.baseAmbient)
+	}
+}
+
+// scaleColor умножает яркость цвета
+func scaleColor(c color.RGBA, factor float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(float64(c.R) * factor),
+		G: uint8(float64(c.G) * factor),
+		B: uint8(float64(c.B) * factor),
+		A: c.A,
+	}
+}
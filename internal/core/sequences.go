@@ -0,0 +1,50 @@
+package core
+
+import (
+	"log"
+	"time"
+
+	"nightmare/internal/ai"
+	"nightmare/internal/event"
+	"nightmare/internal/item"
+)
+
+// sequencesDir - каталог с авторскими сценами испуга
+const sequencesDir = "data/sequences"
+
+// loadSequences загружает авторские сцены испуга и подписывает директора на подбор предметов
+func loadSequences(director *ai.Director, events *event.EventManager) {
+	sequences, err := ai.LoadScareSequences(sequencesDir)
+	if err != nil {
+		log.Printf("Не удалось загрузить сцены испуга: %v", err)
+		return
+	}
+	director.AddSequences(sequences)
+
+	events.AddCustomListener("item_added", func(data event.EventData) {
+		if picked, ok := data.Target.(*item.Item); ok {
+			director.ItemPickedUp(picked.Name)
+		}
+	})
+}
+
+// FlickerLights заставляет фонарь мигать заданное время
+func (g *Game) FlickerLights(duration time.Duration) {
+	g.flickerUntil = time.Now().Add(duration)
+}
+
+// Silence обрывает все звуки и не дает появиться новым заданное время
+func (g *Game) Silence(duration time.Duration) {
+	g.sounds.StopAllSounds()
+	g.silenceUntil = time.Now().Add(duration)
+}
+
+// flickering проверяет, мигает ли свет по воле сцены испуга
+func (g *Game) flickering() bool {
+	return time.Now().Before(g.flickerUntil)
+}
+
+// silenced проверяет, держит ли сцена испуга тишину
+func (g *Game) silenced() bool {
+	return time.Now().Before(g.silenceUntil)
+}
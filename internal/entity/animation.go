@@ -0,0 +1,287 @@
+package entity
+
+import "math"
+
+// События анимации
+const (
+	AnimEventHit = "hit" // Момент удара в клипе атаки
+)
+
+// AnimationClip описывает последовательность кадров анимации
+type AnimationClip struct {
+	Name          string
+	Frames        []int
+	FrameTime     int            // Сколько игровых кадров длится один кадр анимации
+	Loop          bool           // Зацикленный клип; иначе клип проигрывается один раз
+	Interruptible bool           // Можно ли прервать клип до его окончания
+	BlendTime     int            // Длительность плавного перехода в этот клип (в игровых кадрах)
+	Events        map[int]string // События по индексу кадра клипа
+}
+
+// Duration возвращает длительность клипа в игровых кадрах
+func (clip *AnimationClip) Duration() int {
+	return len(clip.Frames) * clip.FrameTime
+}
+
+// Animator проигрывает клипы анимации существа и управляет переходами между ними
+type Animator struct {
+	Clips map[string]*AnimationClip
+
+	current  *AnimationClip
+	previous *AnimationClip // Клип, из которого идет плавный переход
+	pending  string         // Клип, ожидающий окончания непрерываемого клипа
+
+	time         int // Время с начала текущего клипа
+	previousTime int // Время предыдущего клипа на момент перехода
+	blendTime    int // Сколько кадров уже длится переход
+
+	events []string // События, произошедшие в последнем обновлении
+}
+
+// NewAnimator создает аниматор с набором клипов
+func NewAnimator(clips map[string]*AnimationClip) *Animator {
+	return &Animator{
+		Clips:  clips,
+		events: []string{},
+	}
+}
+
+// Play переключает аниматор на клип. Повторный вызов для текущего клипа ничего не делает,
+// а непрерываемый клип сначала доигрывается до конца.
+func (a *Animator) Play(name string) {
+	clip, ok := a.Clips[name]
+	if !ok {
+		return
+	}
+
+	if clip == a.current {
+		a.pending = ""
+		return
+	}
+
+	if a.current != nil && !a.current.Interruptible && !a.Finished() {
+		a.pending = name
+		return
+	}
+
+	a.switchTo(clip)
+}
+
+// switchTo начинает проигрывание клипа с плавным переходом от текущего
+func (a *Animator) switchTo(clip *AnimationClip) {
+	a.previous = nil
+	if a.current != nil && clip.BlendTime > 0 {
+		a.previous = a.current
+		a.previousTime = a.time
+	}
+
+	a.current = clip
+	a.pending = ""
+	a.time = 0
+	a.blendTime = 0
+}
+
+// Update продвигает анимацию на один игровой кадр и собирает события
+func (a *Animator) Update() {
+	a.events = a.events[:0]
+
+	if a.current == nil {
+		return
+	}
+
+	// Доигравший непрерываемый клип уступает ожидающему
+	if a.pending != "" && a.Finished() {
+		a.switchTo(a.Clips[a.pending])
+	}
+
+	prevIndex := a.frameIndex()
+	a.time++
+	index := a.frameIndex()
+
+	// Событие срабатывает при входе в кадр (и в самом начале клипа)
+	if index != prevIndex || a.time == 1 {
+		if event, ok := a.current.Events[index]; ok {
+			a.events = append(a.events, event)
+		}
+	}
+
+	if a.previous != nil {
+		a.blendTime++
+		if a.blendTime >= a.current.BlendTime {
+			a.previous = nil
+		}
+	}
+}
+
+// frameIndex возвращает индекс кадра текущего клипа
+func (a *Animator) frameIndex() int {
+	return clipFrameIndex(a.current, a.time)
+}
+
+// clipFrameIndex возвращает индекс кадра клипа в момент времени
+func clipFrameIndex(clip *AnimationClip, time int) int {
+	if clip == nil || len(clip.Frames) == 0 {
+		return 0
+	}
+
+	index := time / max(1, clip.FrameTime)
+	if clip.Loop {
+		return index % len(clip.Frames)
+	}
+	return min(index, len(clip.Frames)-1)
+}
+
+// Frame возвращает текущий кадр анимации
+func (a *Animator) Frame() int {
+	if a.current == nil || len(a.current.Frames) == 0 {
+		return 0
+	}
+	return a.current.Frames[a.frameIndex()]
+}
+
+// BlendFrame возвращает кадр клипа, из которого идет переход, и его вес (от 0 до 1).
+// Вес 0 означает, что перехода нет.
+func (a *Animator) BlendFrame() (int, float64) {
+	if a.previous == nil || len(a.previous.Frames) == 0 {
+		return 0, 0
+	}
+
+	frame := a.previous.Frames[clipFrameIndex(a.previous, a.previousTime)]
+	weight := 1 - float64(a.blendTime)/float64(a.current.BlendTime)
+	return frame, math.Max(0, weight)
+}
+
+// Finished проверяет, доигран ли одноразовый клип
+func (a *Animator) Finished() bool {
+	if a.current == nil || a.current.Loop {
+		return false
+	}
+	return a.time >= a.current.Duration()
+}
+
+// Current возвращает имя текущего клипа
+func (a *Animator) Current() string {
+	if a.current == nil {
+		return ""
+	}
+	return a.current.Name
+}
+
+// Events возвращает события, произошедшие в последнем обновлении
+func (a *Animator) Events() []string {
+	return a.events
+}
+
+// defaultClips создает стандартный набор клипов существа
+func defaultClips(creatureType string) map[string]*AnimationClip {
+	// Быстрые существа быстрее перебирают ногами
+	walkTime := 10
+	if creatureType == "spider" || creatureType == "wendigo" {
+		walkTime = 6
+	}
+
+	clips := []*AnimationClip{
+		{Name: "idle", Frames: []int{0, 1, 2, 1}, FrameTime: 15, Loop: true, Interruptible: true, BlendTime: 8},
+		{Name: "walk", Frames: []int{3, 4, 5, 6, 5, 4}, FrameTime: walkTime, Loop: true, Interruptible: true, BlendTime: 6},
+		{Name: "run", Frames: []int{7, 8, 9, 10, 9, 8}, FrameTime: walkTime / 2, Loop: true, Interruptible: true, BlendTime: 4},
+		{Name: "sneak", Frames: []int{16, 17, 18, 17}, FrameTime: 12, Loop: true, Interruptible: true, BlendTime: 8},
+		{Name: "flee", Frames: []int{19, 20, 21, 22, 21, 20}, FrameTime: walkTime / 2, Loop: true, Interruptible: true, BlendTime: 4},
+		{
+			Name:      "attack",
+			Frames:    []int{11, 12, 13, 14, 15},
+			FrameTime: 6,
+			Events:    map[int]string{2: AnimEventHit}, // Удар на середине замаха
+		},
+	}
+
+	result := make(map[string]*AnimationClip, len(clips))
+	for _, clip := range clips {
+		result[clip.Name] = clip
+	}
+	return result
+}
+
+// stateClip возвращает имя клипа для состояния существа
+func stateClip(state string) string {
+	switch state {
+	case "wander", "search", "mimic", "regroup":
+		return "walk"
+	case "chase":
+		return "run"
+	case "attack":
+		return "attack"
+	case "stalk", "lurk":
+		return "sneak"
+	case "flee":
+		return "flee"
+	default:
+		return "idle"
+	}
+}
+
+// updateAnimation проигрывает клип текущего состояния и обрабатывает события анимации
+func (c *Creature) updateAnimation() {
+	// Замершее существо сохраняет текущий кадр
+	if c.CurrentState == "frozen" {
+		return
+	}
+
+	c.Animator.Play(stateClip(c.CurrentState))
+	c.Animator.Update()
+
+	for _, event := range c.Animator.Events() {
+		if event == AnimEventHit {
+			c.attackHit()
+		}
+	}
+
+	c.animTime++
+	moving := stateClip(c.CurrentState) != "idle" && c.CurrentState != "attack"
+
+	frame := c.Animator.Frame()
+	for i := range c.Parts {
+		c.Parts[i].CurrentAnim = frame
+		c.updateSecondaryMotion(i, moving)
+	}
+}
+
+// updateSecondaryMotion добавляет частям процедурное движение поверх кадров анимации
+func (c *Creature) updateSecondaryMotion(index int, moving bool) {
+	part := &c.Parts[index]
+	t := float64(c.animTime)
+	phase := float64(index)
+
+	part.MotionOffset = Vector2D{}
+	part.MotionRotation = 0
+
+	switch {
+	case c.Type == "phantom":
+		// Призрак медленно дрейфует, все части колышутся вместе с телом
+		part.MotionOffset = Vector2D{
+			X: math.Cos(t*0.03+phase*0.5) * 0.05,
+			Y: math.Sin(t*0.05) * 0.1,
+		}
+
+	case part.Type == "leg":
+		if !moving {
+			return
+		}
+		// Ноги паука шагают двумя группами в противофазе
+		group := float64(index % 2)
+		cycle := math.Sin(t*0.4*c.Speed + group*math.Pi)
+		part.MotionRotation = cycle * 0.3
+		part.MotionOffset = Vector2D{
+			X: math.Cos(part.Rotation) * math.Max(0, cycle) * 0.08,
+			Y: math.Sin(part.Rotation) * math.Max(0, cycle) * 0.08,
+		}
+
+	case part.Type == "tentacle":
+		// Щупальца извиваются постоянно
+		part.MotionRotation = math.Sin(t*0.1+phase*0.7) * 0.4
+
+	case part.Type == "limb" || part.Type == "arm":
+		if moving {
+			part.MotionRotation = math.Sin(t*0.2+phase) * 0.2
+		}
+	}
+}
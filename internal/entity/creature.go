@@ -2,8 +2,9 @@ package entity
 
 import (
 	"math"
-	"math/rand"
 	"time"
+
+	"nightmare/internal/common"
 )
 
 // Типы поведения существ
@@ -37,6 +38,26 @@ type Creature struct {
 	LastSeen       time.Time
 	IsVisible      bool
 	StalkingTime   int
+	Observed       bool         // Игрок смотрит на существо при свете
+	Terrain        Terrain      // Запросы к миру (проходимость, линия видимости)
+	Light          LightSampler // Запросы к освещению
+	Disguised      bool         // Существо выдает себя за игрока
+	CarriesLight   bool         // Существо несет фонарь
+	ReplayPath     []Vector2D   // Маршрут, который повторяет двойник
+	Essence        float64      // Запас сущности тени (от 0 до 1), свет его выжигает
+	Genome         *Genome      // Наследуемые признаки существа
+	ScareScore     float64      // Сколько рассудка существо отняло у игрока
+	Animator       *Animator    // Клипы анимации и переходы между ними
+	MaxHealth      float64
+	Morale         float64 // Боевой дух (от 0 до 1), при падении ниже порога существо убегает
+	Packmates      int     // Сколько сородичей рядом
+	TimesFled      int     // Сколько раз существо убегало от игрока
+	resumeState    string  // Состояние, в которое существо вернется после заморозки
+	replayIndex    int
+	replayStep     int
+	animTime       int // Время для процедурного движения частей
+	staggerTime    int // Сколько кадров существо еще оглушено
+	packCenter     Vector2D
 }
 
 // CreaturePart представляет собой часть существа
@@ -48,6 +69,10 @@ type CreaturePart struct {
 	Scale       float64
 	AnimFrames  []int
 	CurrentAnim int
+
+	// Процедурное движение поверх кадров анимации
+	MotionOffset   Vector2D
+	MotionRotation float64
 }
 
 // NewCreature создает новое существо
@@ -55,70 +80,107 @@ func NewCreature(id int, creatureType string, position Vector2D) *Creature {
 	c := &Creature{
 		ID:             id,
 		Position:       position,
-		Direction:      rand.Float64() * 2 * math.Pi,
-		Speed:          1.0 + rand.Float64()*1.5,
-		Health:         50 + rand.Float64()*50,
+		Direction:      common.Rand().Float64() * 2 * math.Pi,
+		Speed:          1.0 + common.Rand().Float64()*1.5,
+		Health:         50 + common.Rand().Float64()*50,
 		Type:           creatureType,
-		DetectionRange: 10 + rand.Float64()*15,
-		AttackRange:    1.5 + rand.Float64(),
-		AttackDamage:   5 + rand.Float64()*10,
-		SanityDamage:   2 + rand.Float64()*8,
+		DetectionRange: 10 + common.Rand().Float64()*15,
+		AttackRange:    1.5 + common.Rand().Float64(),
+		AttackDamage:   5 + common.Rand().Float64()*10,
+		SanityDamage:   2 + common.Rand().Float64()*8,
 		Parts:          []CreaturePart{},
 		CurrentState:   "idle",
 		StateTime:      0,
-		LastSeen:       time.Now().Add(-10 * time.Minute), // Давно не видели
+		LastSeen:       common.Now().Add(-10 * time.Minute), // Давно не видели
 		IsVisible:      false,
 		StalkingTime:   0,
+		Animator:       NewAnimator(defaultClips(creatureType)),
 	}
 
 	// Устанавливаем поведение на основе типа
 	switch creatureType {
 	case "shadow":
 		c.BehaviorType = BehaviorStalker
-		c.Speed = 0.8 + rand.Float64()*0.4
-		c.SanityDamage = 10 + rand.Float64()*15
+		c.Speed = 0.8 + common.Rand().Float64()*0.4
+		c.SanityDamage = 10 + common.Rand().Float64()*15
+		c.Essence = 1.0
 	case "spider":
 		c.BehaviorType = BehaviorAggressive
-		c.Speed = 1.5 + rand.Float64()*1.0
-		c.AttackDamage = 15 + rand.Float64()*10
+		c.Speed = 1.5 + common.Rand().Float64()*1.0
+		c.AttackDamage = 15 + common.Rand().Float64()*10
 	case "phantom":
 		c.BehaviorType = BehaviorPatrol
-		c.Speed = 1.0 + rand.Float64()*0.5
-		c.SanityDamage = 5 + rand.Float64()*10
+		c.Speed = 1.0 + common.Rand().Float64()*0.5
+		c.SanityDamage = 5 + common.Rand().Float64()*10
 	case "wendigo":
 		c.BehaviorType = BehaviorHunter
-		c.Speed = 2.0 + rand.Float64()*1.0
-		c.AttackDamage = 20 + rand.Float64()*15
+		c.Speed = 2.0 + common.Rand().Float64()*1.0
+		c.AttackDamage = 20 + common.Rand().Float64()*15
 	case "faceless":
 		c.BehaviorType = BehaviorStalker
-		c.Speed = 0.5 + rand.Float64()*0.3
-		c.SanityDamage = 15 + rand.Float64()*10
+		c.Speed = 0.5 + common.Rand().Float64()*0.3
+		c.SanityDamage = 15 + common.Rand().Float64()*10
+	case "doppelganger":
+		// Двойник до разоблачения ходит как игрок и несет фонарь
+		c.BehaviorType = BehaviorStalker
+		c.Speed = 1.0 + common.Rand().Float64()*0.3
+		c.AttackDamage = 15 + common.Rand().Float64()*10
+		c.SanityDamage = 20 + common.Rand().Float64()*10
+		c.Disguised = true
+		c.CarriesLight = true
 	default:
 		c.BehaviorType = BehaviorPassive
 	}
 
+	c.MaxHealth = c.Health
+	c.Morale = moraleProfileFor(creatureType).Courage
+
 	return c
 }
 
-// Update обновляет состояние существа
-func (c *Creature) Update(worldWidth, worldHeight int) {
+// Update обновляет состояние существа; bounds - область мира, в которой оно может действовать
+func (c *Creature) Update(bounds common.Bounds) {
 	c.StateTime++
 
+	// Оглушенное существо не действует
+	if c.staggerTime > 0 {
+		c.staggerTime--
+		return
+	}
+
+	// Двойник выдает себя, когда игрок подходит вплотную, откуда бы он ни взялся
+	if c.Disguised && c.PlayerTarget != nil && c.distanceTo(c.PlayerTarget.Position) < MimicRevealDistance {
+		c.Reveal()
+	}
+
+	// Безликий двигается, только когда на него не смотрят
+	if c.Type == "faceless" {
+		c.updateObservation()
+	}
+
+	// Тень существует только в темноте
+	if c.Type == "shadow" && c.updateShadow(bounds) {
+		return
+	}
+
+	// Раненое или напуганное существо может обратиться в бегство
+	c.updateMorale()
+
 	// Обновляем состояние на основе текущего поведения
 	switch c.CurrentState {
 	case "idle":
 		// В состоянии покоя существо периодически меняет направление
-		if c.StateTime > 60+rand.Intn(120) {
-			c.Direction = rand.Float64() * 2 * math.Pi
+		if c.StateTime > 60+common.Rand().Intn(120) {
+			c.Direction = common.Rand().Float64() * 2 * math.Pi
 			c.StateTime = 0
 
 			// Иногда переходим в состояние блуждания
-			if rand.Float64() < 0.7 {
+			if common.Rand().Float64() < 0.7 {
 				c.CurrentState = "wander"
 				// Выбираем случайную точку назначения
 				c.TargetPos = Vector2D{
-					X: rand.Float64() * float64(worldWidth),
-					Y: rand.Float64() * float64(worldHeight),
+					X: bounds.Min.X + common.Rand().Float64()*(bounds.Max.X-bounds.Min.X),
+					Y: bounds.Min.Y + common.Rand().Float64()*(bounds.Max.Y-bounds.Min.Y),
 				}
 			}
 		}
@@ -162,20 +224,12 @@ func (c *Creature) Update(worldWidth, worldHeight int) {
 		}
 
 	case "attack":
-		// Анимация атаки длится 30 кадров
-		if c.StateTime == 15 { // Середина анимации - момент атаки
-			if c.PlayerTarget != nil {
-				dist := c.distanceTo(c.PlayerTarget.Position)
-				if dist < c.AttackRange {
-					// Наносим урон игроку
-					c.PlayerTarget.TakeDamage(c.AttackDamage)
-					c.PlayerTarget.ReduceSanity(c.SanityDamage)
-				}
-			}
-		}
-
-		if c.StateTime >= 30 {
+		// Урон наносится событием клипа атаки, состояние длится до конца клипа
+		if c.Animator.Current() == "attack" && c.Animator.Finished() {
 			c.CurrentState = "chase"
+			if c.Type == "faceless" {
+				c.CurrentState = "lurk"
+			}
 			c.StateTime = 0
 		}
 
@@ -192,28 +246,17 @@ func (c *Creature) Update(worldWidth, worldHeight int) {
 			c.StateTime = 0
 			// Выбираем новую случайную точку
 			c.TargetPos = Vector2D{
-				X: c.TargetPos.X + (rand.Float64()*20 - 10),
-				Y: c.TargetPos.Y + (rand.Float64()*20 - 10),
+				X: c.TargetPos.X + (common.Rand().Float64()*20 - 10),
+				Y: c.TargetPos.Y + (common.Rand().Float64()*20 - 10),
 			}
 			// Ограничиваем координаты в пределах мира
-			if c.TargetPos.X < 0 {
-				c.TargetPos.X = 0
-			}
-			if c.TargetPos.Y < 0 {
-				c.TargetPos.Y = 0
-			}
-			if c.TargetPos.X >= float64(worldWidth) {
-				c.TargetPos.X = float64(worldWidth) - 1
-			}
-			if c.TargetPos.Y >= float64(worldHeight) {
-				c.TargetPos.Y = float64(worldHeight) - 1
-			}
+			c.TargetPos = FromCommonVector(bounds.Clamp(c.TargetPos.ToCommonVector()))
 		}
 
 	case "stalk":
 		// Преследуем игрока, но держимся на расстоянии
 		if c.PlayerTarget != nil {
-			targetDist := 8.0 + rand.Float64()*4.0 // Дистанция преследования
+			targetDist := 8.0 + common.Rand().Float64()*4.0 // Дистанция преследования
 			dist := c.distanceTo(c.PlayerTarget.Position)
 
 			if dist < targetDist-2.0 {
@@ -234,7 +277,7 @@ func (c *Creature) Update(worldWidth, worldHeight int) {
 			}
 
 			// Иногда переходим в режим атаки
-			if c.StalkingTime > 300 && rand.Float64() < 0.01 {
+			if c.StalkingTime > 300 && common.Rand().Float64() < 0.01 {
 				c.CurrentState = "chase"
 				c.StateTime = 0
 				c.StalkingTime = 0
@@ -246,6 +289,21 @@ func (c *Creature) Update(worldWidth, worldHeight int) {
 			c.StateTime = 0
 		}
 
+	case "frozen":
+		// Существо неподвижно, пока на него смотрят
+
+	case "lurk":
+		// Безликий подкрадывается, пока игрок не смотрит
+		c.updateLurk(bounds)
+
+	case "mimic":
+		// Двойник повторяет маршрут игрока
+		c.updateMimic()
+
+	case "regroup":
+		// Собираемся со стаей и набираемся смелости
+		c.updateRegroup()
+
 	case "flee":
 		// Убегаем от игрока
 		if c.PlayerTarget != nil {
@@ -253,10 +311,10 @@ func (c *Creature) Update(worldWidth, worldHeight int) {
 			c.Direction = c.smoothDirection(c.Direction, dir, 0.2)
 			c.moveForward()
 
-			// Если убежали достаточно далеко, переходим в режим блуждания
+			// Если убежали достаточно далеко, перегруппировываемся
 			dist := c.distanceTo(c.PlayerTarget.Position)
 			if dist > c.DetectionRange*2 || c.StateTime > 300 {
-				c.CurrentState = "wander"
+				c.CurrentState = "regroup"
 				c.StateTime = 0
 			}
 		} else {
@@ -266,21 +324,40 @@ func (c *Creature) Update(worldWidth, worldHeight int) {
 	}
 
 	// Обновляем анимацию для всех частей
-	for i := range c.Parts {
-		c.updatePartAnimation(&c.Parts[i])
+	c.updateAnimation()
+}
+
+// attackHit наносит удар игроку в момент удара клипа атаки
+func (c *Creature) attackHit() {
+	// Существо, которое бросило атаку (например, обратилось в бегство), не бьет
+	if c.PlayerTarget == nil || c.CurrentState != "attack" {
+		return
+	}
+
+	dist := c.distanceTo(c.PlayerTarget.Position)
+	if dist < c.AttackRange {
+		// Наносим урон игроку
+		c.PlayerTarget.TakeDamage(c.AttackDamage)
+		c.PlayerTarget.ReduceSanity(c.SanityDamage)
+		c.ScareScore += c.SanityDamage
 	}
 }
 
 // SetTarget устанавливает игрока в качестве цели
 func (c *Creature) SetTarget(player *Player) {
 	c.PlayerTarget = player
-	c.LastSeen = time.Now()
+	c.LastSeen = common.Now()
+
+	// Замаскированный двойник продолжает притворяться, пока игрок не подойдет
+	if c.Disguised {
+		return
+	}
 
 	// Реагируем на обнаружение игрока в зависимости от типа поведения
 	switch c.BehaviorType {
 	case BehaviorPassive:
 		// Пассивные существа не реагируют или убегают
-		if rand.Float64() < 0.7 {
+		if common.Rand().Float64() < 0.7 {
 			c.CurrentState = "flee"
 		} else {
 			c.CurrentState = "idle"
@@ -291,8 +368,12 @@ func (c *Creature) SetTarget(player *Player) {
 		c.CurrentState = "chase"
 
 	case BehaviorStalker:
-		// Сталкеры наблюдают издалека
-		c.CurrentState = "stalk"
+		// Сталкеры наблюдают издалека, безликий сразу начинает подкрадываться
+		if c.Type == "faceless" {
+			c.CurrentState = "lurk"
+		} else {
+			c.CurrentState = "stalk"
+		}
 
 	case BehaviorFleeing:
 		// Убегающие всегда убегают
@@ -300,7 +381,7 @@ func (c *Creature) SetTarget(player *Player) {
 
 	case BehaviorPatrol:
 		// Патрульные могут атаковать или продолжать патрулирование
-		if rand.Float64() < 0.5 {
+		if common.Rand().Float64() < 0.5 {
 			c.CurrentState = "chase"
 		}
 
@@ -326,6 +407,9 @@ func (c *Creature) LoseTarget() {
 func (c *Creature) TakeDamage(amount float64) {
 	c.Health -= amount
 
+	// Раненый двойник больше не притворяется
+	c.Reveal()
+
 	// Реакция на получение урона
 	if c.Health > 0 {
 		switch c.BehaviorType {
@@ -339,7 +423,7 @@ func (c *Creature) TakeDamage(amount float64) {
 
 		case BehaviorStalker:
 			// Сталкеры могут атаковать или скрыться
-			if rand.Float64() < 0.5 {
+			if common.Rand().Float64() < 0.5 {
 				c.CurrentState = "chase"
 			} else {
 				c.CurrentState = "flee"
@@ -347,7 +431,7 @@ func (c *Creature) TakeDamage(amount float64) {
 
 		case BehaviorPatrol:
 			// Патрульные могут атаковать или отступить
-			if rand.Float64() < 0.7 {
+			if common.Rand().Float64() < 0.7 {
 				c.CurrentState = "chase"
 			} else {
 				c.CurrentState = "flee"
@@ -358,6 +442,31 @@ func (c *Creature) TakeDamage(amount float64) {
 	}
 }
 
+// Knockback отбрасывает существо в указанном направлении
+func (c *Creature) Knockback(direction, force float64) {
+	next := Vector2D{
+		X: c.Position.X + math.Cos(direction)*force,
+		Y: c.Position.Y + math.Sin(direction)*force,
+	}
+
+	// Препятствия останавливают отброс
+	if c.Terrain != nil && c.Terrain.CheckCollision(next.ToCommonVector()) {
+		return
+	}
+
+	c.Position = next
+}
+
+// Stagger оглушает существо на указанное количество кадров
+func (c *Creature) Stagger(frames int) {
+	c.staggerTime = max(c.staggerTime, frames)
+}
+
+// IsStaggered проверяет, оглушено ли существо
+func (c *Creature) IsStaggered() bool {
+	return c.staggerTime > 0
+}
+
 // IsDead проверяет, мертво ли существо
 func (c *Creature) IsDead() bool {
 	return c.Health <= 0
@@ -365,10 +474,20 @@ func (c *Creature) IsDead() bool {
 
 // moveForward перемещает существо вперед
 func (c *Creature) moveForward() {
-	dx := c.Speed * math.Cos(c.Direction)
-	dy := c.Speed * math.Sin(c.Direction)
-	c.Position.X += dx
-	c.Position.Y += dy
+	c.moveBy(c.Speed)
+}
+
+// moveBy перемещает существо вперед на указанное расстояние
+func (c *Creature) moveBy(step float64) {
+	dx := step * math.Cos(c.Direction)
+	dy := step * math.Sin(c.Direction)
+
+	next := Vector2D{X: c.Position.X + dx, Y: c.Position.Y + dy}
+	if !c.canEnter(next) {
+		return
+	}
+
+	c.Position = next
 }
 
 // getDirectionTo возвращает направление к точке
@@ -411,38 +530,3 @@ func (c *Creature) smoothDirection(current, target, factor float64) float64 {
 
 	return newDir
 }
-
-// updatePartAnimation обновляет анимацию части существа
-func (c *Creature) updatePartAnimation(part *CreaturePart) {
-	// В зависимости от текущего состояния выбираем анимацию
-	var frames []int
-
-	switch c.CurrentState {
-	case "idle":
-		frames = []int{0, 1, 2, 1} // Простая анимация дыхания
-
-	case "wander", "search":
-		frames = []int{3, 4, 5, 6, 5, 4} // Анимация ходьбы
-
-	case "chase":
-		frames = []int{7, 8, 9, 10, 9, 8} // Быстрая анимация бега
-
-	case "attack":
-		frames = []int{11, 12, 13, 14, 15} // Анимация атаки
-
-	case "stalk":
-		frames = []int{16, 17, 18, 17} // Анимация скрытного передвижения
-
-	case "flee":
-		frames = []int{19, 20, 21, 22, 21, 20} // Анимация бегства
-
-	default:
-		frames = []int{0}
-	}
-
-	// Обновляем текущий кадр анимации
-	if len(frames) > 0 {
-		frameIdx := (c.StateTime / 10) % len(frames)
-		part.CurrentAnim = frames[frameIdx]
-	}
-}
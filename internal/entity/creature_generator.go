@@ -2,8 +2,8 @@ package entity
 
 import (
 	"math"
-	"math/rand"
 
+	"nightmare/internal/common"
 	"nightmare/internal/util"
 )
 
@@ -17,7 +17,7 @@ type CreatureGenerator struct {
 // NewCreatureGenerator создает новый генератор существ
 func NewCreatureGenerator() *CreatureGenerator {
 	return &CreatureGenerator{
-		noise:        util.NewNoiseGenerator(rand.Int63()),
+		noise:        util.NewNoiseGenerator(common.Rand().Int63()),
 		nextID:       1,
 		textureAtlas: make([]int, 0),
 	}
@@ -36,6 +36,43 @@ func (g *CreatureGenerator) GenerateCreature(creatureType string, position Vecto
 	// Генерируем части тела существа
 	g.generateParts(creature)
 
+	// Запоминаем геном, чтобы существо могло стать родителем
+	creature.Genome = GenomeFromCreature(creature)
+
+	return creature
+}
+
+// GenerateFromGenome создает существо по геному
+func (g *CreatureGenerator) GenerateFromGenome(genome *Genome, position Vector2D) *Creature {
+	creature := NewCreature(g.nextID, genome.BodyPlan, position)
+	g.nextID++
+
+	// Характеристики берем из генома
+	creature.Speed = genome.Speed
+	creature.Health = genome.Health
+	creature.MaxHealth = genome.Health
+	creature.AttackDamage = genome.AttackDamage
+	creature.SanityDamage = genome.SanityDamage
+
+	// Группы частей раскладываем по кругу вокруг центра тела
+	for _, gene := range genome.Parts {
+		for i := 0; i < gene.Count; i++ {
+			angle := float64(i) * (2 * math.Pi / float64(gene.Count))
+
+			creature.Parts = append(creature.Parts, CreaturePart{
+				Type:        gene.Type,
+				TextureID:   gene.TextureID,
+				Position:    Vector2D{X: math.Cos(angle) * gene.Reach, Y: math.Sin(angle) * gene.Reach},
+				Rotation:    angle,
+				Scale:       gene.Scale,
+				AnimFrames:  []int{0, 1, 2, 3},
+				CurrentAnim: 0,
+			})
+		}
+	}
+
+	creature.Genome = genome.Clone()
+
 	return creature
 }
 
@@ -52,6 +89,8 @@ func (g *CreatureGenerator) generateParts(creature *Creature) {
 		g.generateWendigoParts(creature)
 	case "faceless":
 		g.generateFacelessParts(creature)
+	case "doppelganger":
+		g.generateDoppelgangerParts(creature)
 	default:
 		g.generateGenericParts(creature)
 	}
@@ -66,16 +105,16 @@ func (g *CreatureGenerator) generateShadowParts(creature *Creature) {
 		TextureID:   bodyTexture,
 		Position:    Vector2D{X: 0, Y: 0},
 		Rotation:    0,
-		Scale:       1.0 + rand.Float64()*0.3,
+		Scale:       1.0 + common.Rand().Float64()*0.3,
 		AnimFrames:  []int{0, 1, 2, 3},
 		CurrentAnim: 0,
 	})
 
 	// Конечности (случайное количество от 2 до 5)
-	numLimbs := 2 + rand.Intn(4)
+	numLimbs := 2 + common.Rand().Intn(4)
 	for i := 0; i < numLimbs; i++ {
 		angle := float64(i) * (2 * math.Pi / float64(numLimbs))
-		dist := 0.5 + rand.Float64()*0.3
+		dist := 0.5 + common.Rand().Float64()*0.3
 		limbTexture := g.getRandomTextureID()
 
 		creature.Parts = append(creature.Parts, CreaturePart{
@@ -83,21 +122,21 @@ func (g *CreatureGenerator) generateShadowParts(creature *Creature) {
 			TextureID:   limbTexture,
 			Position:    Vector2D{X: math.Cos(angle) * dist, Y: math.Sin(angle) * dist},
 			Rotation:    angle,
-			Scale:       0.5 + rand.Float64()*0.5,
+			Scale:       0.5 + common.Rand().Float64()*0.5,
 			AnimFrames:  []int{0, 1, 2, 3},
 			CurrentAnim: 0,
 		})
 	}
 
 	// Лицо (опционально)
-	if rand.Float64() < 0.7 {
+	if common.Rand().Float64() < 0.7 {
 		faceTexture := g.getRandomTextureID()
 		creature.Parts = append(creature.Parts, CreaturePart{
 			Type:        "face",
 			TextureID:   faceTexture,
 			Position:    Vector2D{X: 0, Y: 0},
 			Rotation:    0,
-			Scale:       0.7 + rand.Float64()*0.3,
+			Scale:       0.7 + common.Rand().Float64()*0.3,
 			AnimFrames:  []int{0, 1, 2, 3},
 			CurrentAnim: 0,
 		})
@@ -113,20 +152,20 @@ func (g *CreatureGenerator) generateSpiderParts(creature *Creature) {
 		TextureID:   bodyTexture,
 		Position:    Vector2D{X: 0, Y: 0},
 		Rotation:    0,
-		Scale:       0.8 + rand.Float64()*0.4,
+		Scale:       0.8 + common.Rand().Float64()*0.4,
 		AnimFrames:  []int{0, 1, 2, 3},
 		CurrentAnim: 0,
 	})
 
 	// Голова
 	headTexture := g.getRandomTextureID()
-	headOffset := Vector2D{X: 0.4 + rand.Float64()*0.2, Y: 0}
+	headOffset := Vector2D{X: 0.4 + common.Rand().Float64()*0.2, Y: 0}
 	creature.Parts = append(creature.Parts, CreaturePart{
 		Type:        "head",
 		TextureID:   headTexture,
 		Position:    headOffset,
 		Rotation:    0,
-		Scale:       0.5 + rand.Float64()*0.3,
+		Scale:       0.5 + common.Rand().Float64()*0.3,
 		AnimFrames:  []int{0, 1, 2, 3},
 		CurrentAnim: 0,
 	})
@@ -134,10 +173,10 @@ func (g *CreatureGenerator) generateSpiderParts(creature *Creature) {
 	// Ноги (8 штук)
 	for i := 0; i < 8; i++ {
 		angle := float64(i) * (2 * math.Pi / 8)
-		dist := 0.5 + rand.Float64()*0.2
+		dist := 0.5 + common.Rand().Float64()*0.2
 		legTexture := g.getRandomTextureID()
 
-		scale := 0.7 + rand.Float64()*0.4
+		scale := 0.7 + common.Rand().Float64()*0.4
 		// Более длинные передние ноги
 		if i < 2 {
 			scale *= 1.3
@@ -164,7 +203,7 @@ func (g *CreatureGenerator) generatePhantomParts(creature *Creature) {
 		TextureID:   bodyTexture,
 		Position:    Vector2D{X: 0, Y: 0},
 		Rotation:    0,
-		Scale:       1.2 + rand.Float64()*0.6,
+		Scale:       1.2 + common.Rand().Float64()*0.6,
 		AnimFrames:  []int{0, 1, 2, 3},
 		CurrentAnim: 0,
 	})
@@ -174,18 +213,18 @@ func (g *CreatureGenerator) generatePhantomParts(creature *Creature) {
 	creature.Parts = append(creature.Parts, CreaturePart{
 		Type:        "tail",
 		TextureID:   tailTexture,
-		Position:    Vector2D{X: 0, Y: 0.5 + rand.Float64()*0.3},
+		Position:    Vector2D{X: 0, Y: 0.5 + common.Rand().Float64()*0.3},
 		Rotation:    0,
-		Scale:       0.8 + rand.Float64()*0.4,
+		Scale:       0.8 + common.Rand().Float64()*0.4,
 		AnimFrames:  []int{0, 1, 2, 3},
 		CurrentAnim: 0,
 	})
 
 	// Руки (2-4 штуки)
-	numArms := 2 + rand.Intn(3)
+	numArms := 2 + common.Rand().Intn(3)
 	for i := 0; i < numArms; i++ {
 		angle := float64(i) * (2 * math.Pi / float64(numArms))
-		dist := 0.4 + rand.Float64()*0.3
+		dist := 0.4 + common.Rand().Float64()*0.3
 		armTexture := g.getRandomTextureID()
 
 		creature.Parts = append(creature.Parts, CreaturePart{
@@ -193,7 +232,7 @@ func (g *CreatureGenerator) generatePhantomParts(creature *Creature) {
 			TextureID:   armTexture,
 			Position:    Vector2D{X: math.Cos(angle) * dist, Y: math.Sin(angle)*dist - 0.2},
 			Rotation:    angle,
-			Scale:       0.6 + rand.Float64()*0.5,
+			Scale:       0.6 + common.Rand().Float64()*0.5,
 			AnimFrames:  []int{0, 1, 2, 3},
 			CurrentAnim: 0,
 		})
@@ -204,9 +243,9 @@ func (g *CreatureGenerator) generatePhantomParts(creature *Creature) {
 	creature.Parts = append(creature.Parts, CreaturePart{
 		Type:        "face",
 		TextureID:   faceTexture,
-		Position:    Vector2D{X: 0, Y: -0.3 - rand.Float64()*0.1},
+		Position:    Vector2D{X: 0, Y: -0.3 - common.Rand().Float64()*0.1},
 		Rotation:    0,
-		Scale:       0.6 + rand.Float64()*0.3,
+		Scale:       0.6 + common.Rand().Float64()*0.3,
 		AnimFrames:  []int{0, 1, 2, 3},
 		CurrentAnim: 0,
 	})
@@ -221,7 +260,7 @@ func (g *CreatureGenerator) generateWendigoParts(creature *Creature) {
 		TextureID:   bodyTexture,
 		Position:    Vector2D{X: 0, Y: 0},
 		Rotation:    0,
-		Scale:       1.0 + rand.Float64()*0.4,
+		Scale:       1.0 + common.Rand().Float64()*0.4,
 		AnimFrames:  []int{0, 1, 2, 3},
 		CurrentAnim: 0,
 	})
@@ -231,9 +270,9 @@ func (g *CreatureGenerator) generateWendigoParts(creature *Creature) {
 	creature.Parts = append(creature.Parts, CreaturePart{
 		Type:        "head",
 		TextureID:   headTexture,
-		Position:    Vector2D{X: 0, Y: -0.7 - rand.Float64()*0.2},
+		Position:    Vector2D{X: 0, Y: -0.7 - common.Rand().Float64()*0.2},
 		Rotation:    0,
-		Scale:       0.9 + rand.Float64()*0.3,
+		Scale:       0.9 + common.Rand().Float64()*0.3,
 		AnimFrames:  []int{0, 1, 2, 3},
 		CurrentAnim: 0,
 	})
@@ -246,9 +285,9 @@ func (g *CreatureGenerator) generateWendigoParts(creature *Creature) {
 		creature.Parts = append(creature.Parts, CreaturePart{
 			Type:        "horn",
 			TextureID:   hornTexture,
-			Position:    Vector2D{X: math.Cos(angle) * 0.4, Y: -0.9 - rand.Float64()*0.3},
+			Position:    Vector2D{X: math.Cos(angle) * 0.4, Y: -0.9 - common.Rand().Float64()*0.3},
 			Rotation:    angle - math.Pi/2,
-			Scale:       0.7 + rand.Float64()*0.5,
+			Scale:       0.7 + common.Rand().Float64()*0.5,
 			AnimFrames:  []int{0, 1, 2, 3},
 			CurrentAnim: 0,
 		})
@@ -262,9 +301,9 @@ func (g *CreatureGenerator) generateWendigoParts(creature *Creature) {
 		creature.Parts = append(creature.Parts, CreaturePart{
 			Type:        "arm",
 			TextureID:   armTexture,
-			Position:    Vector2D{X: side * (0.5 + rand.Float64()*0.1), Y: -0.2},
+			Position:    Vector2D{X: side * (0.5 + common.Rand().Float64()*0.1), Y: -0.2},
 			Rotation:    side * math.Pi / 8,
-			Scale:       1.2 + rand.Float64()*0.6,
+			Scale:       1.2 + common.Rand().Float64()*0.6,
 			AnimFrames:  []int{0, 1, 2, 3},
 			CurrentAnim: 0,
 		})
@@ -278,9 +317,9 @@ func (g *CreatureGenerator) generateWendigoParts(creature *Creature) {
 		creature.Parts = append(creature.Parts, CreaturePart{
 			Type:        "leg",
 			TextureID:   legTexture,
-			Position:    Vector2D{X: side * (0.3 + rand.Float64()*0.1), Y: 0.6},
+			Position:    Vector2D{X: side * (0.3 + common.Rand().Float64()*0.1), Y: 0.6},
 			Rotation:    side * math.Pi / 10,
-			Scale:       1.0 + rand.Float64()*0.4,
+			Scale:       1.0 + common.Rand().Float64()*0.4,
 			AnimFrames:  []int{0, 1, 2, 3},
 			CurrentAnim: 0,
 		})
@@ -296,7 +335,7 @@ func (g *CreatureGenerator) generateFacelessParts(creature *Creature) {
 		TextureID:   bodyTexture,
 		Position:    Vector2D{X: 0, Y: 0},
 		Rotation:    0,
-		Scale:       1.5 + rand.Float64()*0.5,
+		Scale:       1.5 + common.Rand().Float64()*0.5,
 		AnimFrames:  []int{0, 1, 2, 3},
 		CurrentAnim: 0,
 	})
@@ -306,15 +345,15 @@ func (g *CreatureGenerator) generateFacelessParts(creature *Creature) {
 	creature.Parts = append(creature.Parts, CreaturePart{
 		Type:        "head",
 		TextureID:   headTexture,
-		Position:    Vector2D{X: 0, Y: -0.8 - rand.Float64()*0.2},
+		Position:    Vector2D{X: 0, Y: -0.8 - common.Rand().Float64()*0.2},
 		Rotation:    0,
-		Scale:       0.7 + rand.Float64()*0.2,
+		Scale:       0.7 + common.Rand().Float64()*0.2,
 		AnimFrames:  []int{0, 1, 2, 3},
 		CurrentAnim: 0,
 	})
 
 	// Руки (несколько пар, длинные)
-	numArmPairs := 2 + rand.Intn(2)
+	numArmPairs := 2 + common.Rand().Intn(2)
 	for i := 0; i < numArmPairs; i++ {
 		for j := 0; j < 2; j++ {
 			side := float64(j*2 - 1)
@@ -325,9 +364,9 @@ func (g *CreatureGenerator) generateFacelessParts(creature *Creature) {
 			creature.Parts = append(creature.Parts, CreaturePart{
 				Type:        "arm",
 				TextureID:   armTexture,
-				Position:    Vector2D{X: side * (0.4 + rand.Float64()*0.2), Y: yOffset},
-				Rotation:    side * (math.Pi/4 + rand.Float64()*math.Pi/8),
-				Scale:       1.3 + rand.Float64()*0.7,
+				Position:    Vector2D{X: side * (0.4 + common.Rand().Float64()*0.2), Y: yOffset},
+				Rotation:    side * (math.Pi/4 + common.Rand().Float64()*math.Pi/8),
+				Scale:       1.3 + common.Rand().Float64()*0.7,
 				AnimFrames:  []int{0, 1, 2, 3},
 				CurrentAnim: 0,
 			})
@@ -335,11 +374,73 @@ func (g *CreatureGenerator) generateFacelessParts(creature *Creature) {
 	}
 }
 
+// generateDoppelgangerParts генерирует части тела для двойника.
+// Издалека силуэт повторяет игрока: голова, две руки, две ноги и фонарь.
+func (g *CreatureGenerator) generateDoppelgangerParts(creature *Creature) {
+	// Тело
+	creature.Parts = append(creature.Parts, CreaturePart{
+		Type:        "body",
+		TextureID:   g.getRandomTextureID(),
+		Position:    Vector2D{X: 0, Y: 0},
+		Rotation:    0,
+		Scale:       1.0,
+		AnimFrames:  []int{0, 1, 2, 3},
+		CurrentAnim: 0,
+	})
+
+	// Голова (лицо появляется только вблизи)
+	creature.Parts = append(creature.Parts, CreaturePart{
+		Type:        "head",
+		TextureID:   g.getRandomTextureID(),
+		Position:    Vector2D{X: 0, Y: -0.6},
+		Rotation:    0,
+		Scale:       0.5,
+		AnimFrames:  []int{0, 1, 2, 3},
+		CurrentAnim: 0,
+	})
+
+	// Руки и ноги, чуть длиннее человеческих
+	for i := 0; i < 2; i++ {
+		side := float64(i*2 - 1)
+
+		creature.Parts = append(creature.Parts, CreaturePart{
+			Type:        "arm",
+			TextureID:   g.getRandomTextureID(),
+			Position:    Vector2D{X: side * 0.4, Y: -0.2},
+			Rotation:    side * math.Pi / 16,
+			Scale:       0.9 + common.Rand().Float64()*0.2,
+			AnimFrames:  []int{0, 1, 2, 3},
+			CurrentAnim: 0,
+		})
+
+		creature.Parts = append(creature.Parts, CreaturePart{
+			Type:        "leg",
+			TextureID:   g.getRandomTextureID(),
+			Position:    Vector2D{X: side * 0.2, Y: 0.6},
+			Rotation:    0,
+			Scale:       1.0 + common.Rand().Float64()*0.1,
+			AnimFrames:  []int{0, 1, 2, 3},
+			CurrentAnim: 0,
+		})
+	}
+
+	// Фонарь в руке, как у игрока
+	creature.Parts = append(creature.Parts, CreaturePart{
+		Type:        "light",
+		TextureID:   g.getRandomTextureID(),
+		Position:    Vector2D{X: 0.5, Y: -0.1},
+		Rotation:    0,
+		Scale:       0.3,
+		AnimFrames:  []int{0},
+		CurrentAnim: 0,
+	})
+}
+
 // generateGenericParts генерирует части тела для неизвестного типа существа
 func (g *CreatureGenerator) generateGenericParts(creature *Creature) {
 	// Используем шум для определения формы
-	seed := rand.Float64() * 100
-	complexity := 0.5 + rand.Float64()*0.5
+	seed := common.Rand().Float64() * 100
+	complexity := 0.5 + common.Rand().Float64()*0.5
 
 	// Основное тело
 	bodyTexture := g.getRandomTextureID()
@@ -348,51 +449,51 @@ func (g *CreatureGenerator) generateGenericParts(creature *Creature) {
 		TextureID:   bodyTexture,
 		Position:    Vector2D{X: 0, Y: 0},
 		Rotation:    0,
-		Scale:       1.0 + rand.Float64()*0.5,
+		Scale:       1.0 + common.Rand().Float64()*0.5,
 		AnimFrames:  []int{0, 1, 2, 3},
 		CurrentAnim: 0,
 	})
 
 	// Добавляем случайные выступы
-	numProtrusions := 3 + rand.Intn(7)
+	numProtrusions := 3 + common.Rand().Intn(7)
 	for i := 0; i < numProtrusions; i++ {
 		// Используем шум для определения положения
 		angle := float64(i) * (2 * math.Pi / float64(numProtrusions))
 		noise := g.noise.Perlin2D(seed+float64(i), seed+10, complexity)
-		dist := 0.3 + rand.Float64()*0.4 + noise*0.3
+		dist := 0.3 + common.Rand().Float64()*0.4 + noise*0.3
 
 		protrusionTexture := g.getRandomTextureID()
 
 		// Определяем тип выступа
 		partTypes := []string{"limb", "tentacle", "spike", "bulb"}
-		partType := partTypes[rand.Intn(len(partTypes))]
+		partType := partTypes[common.Rand().Intn(len(partTypes))]
 
 		creature.Parts = append(creature.Parts, CreaturePart{
 			Type:        partType,
 			TextureID:   protrusionTexture,
 			Position:    Vector2D{X: math.Cos(angle) * dist, Y: math.Sin(angle) * dist},
 			Rotation:    angle,
-			Scale:       0.4 + rand.Float64()*0.6 + noise*0.3,
+			Scale:       0.4 + common.Rand().Float64()*0.6 + noise*0.3,
 			AnimFrames:  []int{0, 1, 2, 3},
 			CurrentAnim: 0,
 		})
 	}
 
 	// Случайно добавляем "голову" или "глаза"
-	if rand.Float64() < 0.7 {
+	if common.Rand().Float64() < 0.7 {
 		eyeTexture := g.getRandomTextureID()
-		eyeCount := 1 + rand.Intn(4)
+		eyeCount := 1 + common.Rand().Intn(4)
 
 		for i := 0; i < eyeCount; i++ {
 			angle := float64(i) * (2 * math.Pi / float64(eyeCount))
-			eyeDist := 0.2 + rand.Float64()*0.3
+			eyeDist := 0.2 + common.Rand().Float64()*0.3
 
 			creature.Parts = append(creature.Parts, CreaturePart{
 				Type:        "eye",
 				TextureID:   eyeTexture,
 				Position:    Vector2D{X: math.Cos(angle) * eyeDist, Y: math.Sin(angle) * eyeDist},
 				Rotation:    0,
-				Scale:       0.2 + rand.Float64()*0.3,
+				Scale:       0.2 + common.Rand().Float64()*0.3,
 				AnimFrames:  []int{0, 1, 2, 3},
 				CurrentAnim: 0,
 			})
@@ -408,10 +509,10 @@ func (g *CreatureGenerator) GenerateRandomCreature(position Vector2D) *Creature
 	}
 
 	// Выбираем случайный тип
-	creatureType := creatureTypes[rand.Intn(len(creatureTypes))]
+	creatureType := creatureTypes[common.Rand().Intn(len(creatureTypes))]
 
 	// Иногда создаем полностью случайное существо
-	if rand.Float64() < 0.2 {
+	if common.Rand().Float64() < 0.2 {
 		creatureType = "random"
 	}
 
@@ -423,5 +524,5 @@ func (g *CreatureGenerator) getRandomTextureID() int {
 	if len(g.textureAtlas) == 0 {
 		return 0
 	}
-	return g.textureAtlas[rand.Intn(len(g.textureAtlas))]
+	return g.textureAtlas[common.Rand().Intn(len(g.textureAtlas))]
 }
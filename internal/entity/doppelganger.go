@@ -0,0 +1,69 @@
+package entity
+
+// Параметры поведения двойника
+const (
+	MimicRevealDistance = 6.0 // На таком расстоянии двойник сбрасывает маскировку
+	mimicWaypointRadius = 0.5 // Расстояние, на котором точка маршрута считается достигнутой
+)
+
+// SetReplayPath задает маршрут, который двойник будет повторять за игроком
+func (c *Creature) SetReplayPath(path []Vector2D) {
+	if len(path) == 0 {
+		return
+	}
+
+	c.ReplayPath = append([]Vector2D(nil), path...)
+	c.replayIndex = 0
+	c.replayStep = 1
+	c.Position = c.ReplayPath[0]
+	c.Disguised = true
+	c.CarriesLight = true
+	c.CurrentState = "mimic"
+	c.StateTime = 0
+}
+
+// updateMimic ведет двойника по записанному маршруту игрока
+func (c *Creature) updateMimic() {
+	if len(c.ReplayPath) == 0 {
+		c.CurrentState = "idle"
+		c.StateTime = 0
+		return
+	}
+
+	target := c.ReplayPath[c.replayIndex]
+	dist := c.distanceTo(target)
+
+	if dist < mimicWaypointRadius {
+		// Доходим до конца маршрута и идем обратно, как игрок, который возвращается
+		next := c.replayIndex + c.replayStep
+		if next < 0 || next >= len(c.ReplayPath) {
+			c.replayStep = -c.replayStep
+			next = c.replayIndex + c.replayStep
+		}
+		if next >= 0 && next < len(c.ReplayPath) {
+			c.replayIndex = next
+		}
+		return
+	}
+
+	c.Direction = c.smoothDirection(c.Direction, c.getDirectionTo(target), 0.3)
+	c.moveBy(min(c.Speed, dist))
+}
+
+// Reveal сбрасывает маскировку двойника и делает его враждебным
+func (c *Creature) Reveal() {
+	if !c.Disguised {
+		return
+	}
+
+	c.Disguised = false
+	c.BehaviorType = BehaviorHunter
+
+	if c.PlayerTarget != nil {
+		c.CurrentState = "chase"
+	} else {
+		c.CurrentState = "wander"
+		c.TargetPos = c.Position
+	}
+	c.StateTime = 0
+}
@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"math"
+
+	"nightmare/internal/common"
+)
+
+// Параметры поведения безликого
+const (
+	facelessRushMultiplier  = 3.0  // Во сколько раз безликий ускоряется, когда на него не смотрят
+	facelessTeleportChance  = 0.02 // Вероятность телепортации за кадр
+	facelessTeleportMinDist = 12.0 // Телепортируется, только если игрок дальше этого расстояния
+	facelessTeleportTries   = 12   // Количество попыток найти скрытый тайл
+)
+
+// updateObservation замораживает безликого, пока игрок смотрит на него при свете
+func (c *Creature) updateObservation() {
+	observed := c.PlayerTarget != nil && c.PlayerTarget.IsObserving(c.Position, c.Terrain, c.Light)
+	c.Observed = observed
+
+	if observed && c.CurrentState != "frozen" {
+		// Запоминаем состояние, чтобы продолжить его, когда игрок отвернется
+		c.resumeState = c.CurrentState
+		c.CurrentState = "frozen"
+		c.StateTime = 0
+	} else if !observed && c.CurrentState == "frozen" {
+		c.CurrentState = c.resumeState
+		if c.CurrentState == "" || c.CurrentState == "frozen" {
+			c.CurrentState = "idle"
+		}
+		c.StateTime = 0
+	}
+}
+
+// updateLurk сокращает дистанцию до игрока, пока тот не смотрит
+func (c *Creature) updateLurk(bounds common.Bounds) {
+	if c.PlayerTarget == nil {
+		c.CurrentState = "idle"
+		c.StateTime = 0
+		return
+	}
+
+	dist := c.distanceTo(c.PlayerTarget.Position)
+
+	// Издалека иногда перескакивает в скрытую точку ближе к игроку
+	if dist > facelessTeleportMinDist && common.Rand().Float64() < facelessTeleportChance {
+		if pos, ok := c.findHiddenPosition(dist, bounds); ok {
+			c.Position = pos
+			return
+		}
+	}
+
+	// Иначе быстро идет прямо к игроку
+	dir := c.getDirectionTo(c.PlayerTarget.Position)
+	c.Direction = c.smoothDirection(c.Direction, dir, 0.5)
+	c.moveBy(c.Speed * facelessRushMultiplier)
+
+	if c.distanceTo(c.PlayerTarget.Position) < c.AttackRange {
+		c.CurrentState = "attack"
+		c.StateTime = 0
+	}
+}
+
+// findHiddenPosition ищет проходимую точку ближе к игроку, которую он не видит
+func (c *Creature) findHiddenPosition(currentDist float64, bounds common.Bounds) (Vector2D, bool) {
+	player := c.PlayerTarget
+	minDist := c.AttackRange * 2
+	maxDist := currentDist * 0.6
+
+	if maxDist <= minDist {
+		return Vector2D{}, false
+	}
+
+	for i := 0; i < facelessTeleportTries; i++ {
+		// Предпочитаем точки за спиной игрока
+		angle := player.Direction + math.Pi + (common.Rand().Float64()-0.5)*(2*math.Pi-ViewFOV)
+		dist := minDist + common.Rand().Float64()*(maxDist-minDist)
+
+		candidate := Vector2D{
+			X: player.Position.X + math.Cos(angle)*dist,
+			Y: player.Position.Y + math.Sin(angle)*dist,
+		}
+
+		if !bounds.Contains(candidate.ToCommonVector()) {
+			continue
+		}
+
+		if c.Terrain != nil && c.Terrain.CheckCollision(candidate.ToCommonVector()) {
+			continue
+		}
+
+		if player.CanSee(candidate, c.Terrain) {
+			continue
+		}
+
+		return candidate, true
+	}
+
+	return Vector2D{}, false
+}
@@ -0,0 +1,196 @@
+package entity
+
+import (
+	"encoding/json"
+	"math"
+
+	"nightmare/internal/common"
+)
+
+// Genome описывает наследуемые признаки существа: план тела, части и характеристики.
+// Геном сериализуется в JSON, чтобы особенно страшное существо можно было сохранить.
+type Genome struct {
+	BodyPlan     string     `json:"body_plan"` // Базовый тип существа, определяет поведение
+	Parts        []PartGene `json:"parts"`
+	Speed        float64    `json:"speed"`
+	Health       float64    `json:"health"`
+	AttackDamage float64    `json:"attack_damage"`
+	SanityDamage float64    `json:"sanity_damage"`
+	Generation   int        `json:"generation"` // Номер поколения (0 - исходное существо)
+}
+
+// PartGene описывает группу одинаковых частей тела
+type PartGene struct {
+	Type      string  `json:"type"`
+	Count     int     `json:"count"`
+	TextureID int     `json:"texture_id"`
+	Scale     float64 `json:"scale"`
+	Reach     float64 `json:"reach"` // Расстояние от центра тела
+}
+
+// Части, которые могут появиться при мутации
+var mutationPartTypes = []string{"limb", "leg", "tentacle", "spike", "eye"}
+
+// GenomeFromCreature извлекает геном из существующего существа
+func GenomeFromCreature(c *Creature) *Genome {
+	genome := &Genome{
+		BodyPlan:     c.Type,
+		Parts:        []PartGene{},
+		Speed:        c.Speed,
+		Health:       c.Health,
+		AttackDamage: c.AttackDamage,
+		SanityDamage: c.SanityDamage,
+	}
+
+	// Группируем части по типу, сохраняя порядок первого появления
+	index := make(map[string]int)
+	for _, part := range c.Parts {
+		reach := math.Sqrt(part.Position.X*part.Position.X + part.Position.Y*part.Position.Y)
+
+		i, ok := index[part.Type]
+		if !ok {
+			index[part.Type] = len(genome.Parts)
+			genome.Parts = append(genome.Parts, PartGene{
+				Type:      part.Type,
+				Count:     1,
+				TextureID: part.TextureID,
+				Scale:     part.Scale,
+				Reach:     reach,
+			})
+			continue
+		}
+
+		// Усредняем масштаб и вылет частей в группе
+		gene := &genome.Parts[i]
+		n := float64(gene.Count)
+		gene.Scale = (gene.Scale*n + part.Scale) / (n + 1)
+		gene.Reach = (gene.Reach*n + reach) / (n + 1)
+		gene.Count++
+	}
+
+	return genome
+}
+
+// Clone возвращает независимую копию генома
+func (g *Genome) Clone() *Genome {
+	clone := *g
+	clone.Parts = append([]PartGene(nil), g.Parts...)
+	return &clone
+}
+
+// Crossover скрещивает два генома. План тела берется от одного из родителей,
+// части наследуются по группам, а характеристики смешиваются.
+func Crossover(a, b *Genome) *Genome {
+	child := &Genome{
+		BodyPlan:   a.BodyPlan,
+		Parts:      []PartGene{},
+		Generation: max(a.Generation, b.Generation) + 1,
+	}
+	if common.Rand().Float64() < 0.5 {
+		child.BodyPlan = b.BodyPlan
+	}
+
+	t := common.Rand().Float64()
+	child.Speed = lerp(a.Speed, b.Speed, t)
+	child.Health = lerp(a.Health, b.Health, t)
+	child.AttackDamage = lerp(a.AttackDamage, b.AttackDamage, t)
+	child.SanityDamage = lerp(a.SanityDamage, b.SanityDamage, t)
+
+	// Группы частей, которые есть у обоих родителей, берем от случайного;
+	// группы, которые есть только у одного, наследуются с вероятностью 50%
+	fromB := make(map[string]PartGene)
+	for _, gene := range b.Parts {
+		fromB[gene.Type] = gene
+	}
+
+	inherited := make(map[string]bool)
+	for _, gene := range a.Parts {
+		other, shared := fromB[gene.Type]
+		switch {
+		case shared && common.Rand().Float64() < 0.5:
+			child.Parts = append(child.Parts, other)
+		case shared || gene.Type == "body" || common.Rand().Float64() < 0.5:
+			child.Parts = append(child.Parts, gene)
+		default:
+			continue
+		}
+		inherited[gene.Type] = true
+	}
+
+	for _, gene := range b.Parts {
+		if inherited[gene.Type] {
+			continue
+		}
+		if gene.Type == "body" || common.Rand().Float64() < 0.5 {
+			child.Parts = append(child.Parts, gene)
+		}
+	}
+
+	return child
+}
+
+// Mutate случайно изменяет геном. rate - сила и вероятность мутаций (от 0 до 1)
+func (g *Genome) Mutate(rate float64) {
+	g.Speed = math.Max(0.2, mutateValue(g.Speed, rate))
+	g.Health = math.Max(10, mutateValue(g.Health, rate))
+	g.AttackDamage = math.Max(0, mutateValue(g.AttackDamage, rate))
+	g.SanityDamage = math.Max(0, mutateValue(g.SanityDamage, rate))
+
+	for i := range g.Parts {
+		gene := &g.Parts[i]
+		gene.Scale = math.Max(0.1, mutateValue(gene.Scale, rate))
+		gene.Reach = math.Max(0, mutateValue(gene.Reach, rate))
+
+		// Количество частей меняется на одну
+		if gene.Type != "body" && common.Rand().Float64() < rate {
+			if common.Rand().Float64() < 0.5 {
+				gene.Count++
+			} else if gene.Count > 1 {
+				gene.Count--
+			}
+		}
+	}
+
+	// Изредка появляется новая группа частей
+	if common.Rand().Float64() < rate*0.3 {
+		g.Parts = append(g.Parts, PartGene{
+			Type:      mutationPartTypes[common.Rand().Intn(len(mutationPartTypes))],
+			Count:     1 + common.Rand().Intn(4),
+			TextureID: g.randomTextureID(),
+			Scale:     0.3 + common.Rand().Float64()*0.5,
+			Reach:     0.3 + common.Rand().Float64()*0.5,
+		})
+	}
+}
+
+// Marshal сериализует геном в JSON
+func (g *Genome) Marshal() ([]byte, error) {
+	return json.Marshal(g)
+}
+
+// UnmarshalGenome восстанавливает геном из JSON
+func UnmarshalGenome(data []byte) (*Genome, error) {
+	genome := &Genome{}
+	if err := json.Unmarshal(data, genome); err != nil {
+		return nil, err
+	}
+	return genome, nil
+}
+
+// randomTextureID возвращает текстуру одной из существующих частей
+func (g *Genome) randomTextureID() int {
+	if len(g.Parts) == 0 {
+		return 0
+	}
+	return g.Parts[common.Rand().Intn(len(g.Parts))].TextureID
+}
+
+// mutateValue изменяет значение на случайную долю в пределах rate
+func mutateValue(value, rate float64) float64 {
+	return value * (1 + (common.Rand().Float64()*2-1)*rate)
+}
+
+// lerp линейно интерполирует между a и b
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
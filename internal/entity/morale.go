@@ -0,0 +1,149 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// MoraleProfile описывает, насколько существо стойко и как оно реагирует на бегство
+type MoraleProfile struct {
+	Courage        float64 // Базовый боевой дух (от 0 до 1)
+	FleeThreshold  float64 // Ниже этого боевого духа существо убегает
+	LightFear      float64 // Насколько свет подрывает боевой дух
+	PackBonus      float64 // Прибавка за каждого сородича рядом
+	AggressionFear float64 // Насколько пугают недавние атаки игрока
+	Vengeful       bool    // После бегства возвращается мстить, а не осторожничать
+}
+
+// Параметры боевого духа
+const (
+	moraleSmoothing     = 0.05            // Скорость, с которой боевой дух следует за целевым значением
+	moraleRecoverMargin = 0.2             // Насколько боевой дух должен превысить порог, чтобы вернуться
+	moraleMaxPack       = 4               // Больше сородичей не прибавляют храбрости
+	aggressionWindow    = 5 * time.Second // Окно, в котором учитываются атаки игрока
+	regroupMinTime      = 120             // Минимальное время перегруппировки в кадрах
+	regroupMaxTime      = 600             // После этого времени существо возвращается в любом случае
+	fleeMemoryEffect    = 0.1             // Влияние каждого прошлого бегства на боевой дух
+)
+
+// moraleProfiles задает боевой дух для каждого типа существ
+var moraleProfiles = map[string]MoraleProfile{
+	"shadow":       {Courage: 0.6, FleeThreshold: 0.25, LightFear: 0.8, PackBonus: 0.05, AggressionFear: 0.3},
+	"spider":       {Courage: 0.7, FleeThreshold: 0.3, LightFear: 0.3, PackBonus: 0.1, AggressionFear: 0.4, Vengeful: true},
+	"phantom":      {Courage: 0.8, FleeThreshold: 0.2, LightFear: 0.2, AggressionFear: 0.1},
+	"wendigo":      {Courage: 0.9, FleeThreshold: 0.15, LightFear: 0.1, PackBonus: 0.05, AggressionFear: 0.1, Vengeful: true},
+	"faceless":     {Courage: 1.0, FleeThreshold: 0, AggressionFear: 0},
+	"doppelganger": {Courage: 0.8, FleeThreshold: 0.2, LightFear: 0.2, AggressionFear: 0.2, Vengeful: true},
+}
+
+// defaultMoraleProfile используется для неизвестных типов
+var defaultMoraleProfile = MoraleProfile{Courage: 0.5, FleeThreshold: 0.35, LightFear: 0.4, PackBonus: 0.1, AggressionFear: 0.5}
+
+// moraleProfileFor возвращает профиль боевого духа для типа существа
+func moraleProfileFor(creatureType string) MoraleProfile {
+	if profile, ok := moraleProfiles[creatureType]; ok {
+		return profile
+	}
+	return defaultMoraleProfile
+}
+
+// SetPack сообщает существу, сколько сородичей рядом и где центр стаи
+func (c *Creature) SetPack(count int, center Vector2D) {
+	c.Packmates = count
+	c.packCenter = center
+}
+
+// updateMorale пересчитывает боевой дух и при необходимости обращает существо в бегство
+func (c *Creature) updateMorale() {
+	if c.PlayerTarget == nil || c.Disguised {
+		return
+	}
+
+	switch c.CurrentState {
+	case "flee", "regroup", "frozen", "dissolved":
+		return
+	}
+
+	c.Morale += (c.targetMorale() - c.Morale) * moraleSmoothing
+
+	profile := moraleProfileFor(c.Type)
+	if c.Morale < profile.FleeThreshold {
+		c.startFlee()
+	}
+}
+
+// targetMorale вычисляет боевой дух, к которому стремится существо
+func (c *Creature) targetMorale() float64 {
+	profile := moraleProfileFor(c.Type)
+	morale := profile.Courage
+
+	// Раны
+	if c.MaxHealth > 0 {
+		morale -= (1 - c.Health/c.MaxHealth) * 0.6
+	}
+
+	// Свет
+	morale -= c.lightLevel(c.Position) * profile.LightFear
+
+	// Стая
+	morale += float64(min(c.Packmates, moraleMaxPack)) * profile.PackBonus
+
+	// Недавняя агрессия игрока
+	attacks := c.PlayerTarget.RecentActions(ActionAttack, aggressionWindow)
+	morale -= float64(attacks) * 0.1 * profile.AggressionFear
+
+	// Память о прошлых бегствах: мстительные злее, остальные осторожнее
+	if profile.Vengeful {
+		morale += float64(c.TimesFled) * fleeMemoryEffect
+	} else {
+		morale -= float64(c.TimesFled) * fleeMemoryEffect
+	}
+
+	return math.Max(0, math.Min(1, morale))
+}
+
+// startFlee обращает существо в бегство
+func (c *Creature) startFlee() {
+	c.TimesFled++
+	c.CurrentState = "flee"
+	c.StateTime = 0
+}
+
+// updateRegroup ведет существо к стае, пока боевой дух не восстановится
+func (c *Creature) updateRegroup() {
+	// Идем к центру стаи, если она есть
+	if c.Packmates > 0 && c.distanceTo(c.packCenter) > 2.0 {
+		c.Direction = c.smoothDirection(c.Direction, c.getDirectionTo(c.packCenter), 0.1)
+		c.moveForward()
+	}
+
+	// Вдали от игрока боевой дух восстанавливается
+	profile := moraleProfileFor(c.Type)
+	c.Morale = math.Min(profile.Courage, c.Morale+moraleSmoothing*0.2)
+
+	recovered := c.Morale >= profile.FleeThreshold+moraleRecoverMargin
+	if (recovered && c.StateTime > regroupMinTime) || c.StateTime > regroupMaxTime {
+		c.returnAfterRegroup()
+	}
+}
+
+// returnAfterRegroup возвращает существо к игроку после перегруппировки
+func (c *Creature) returnAfterRegroup() {
+	c.StateTime = 0
+
+	if c.PlayerTarget == nil || c.BehaviorType == BehaviorPassive || c.BehaviorType == BehaviorFleeing {
+		c.CurrentState = "wander"
+		c.TargetPos = c.Position
+		return
+	}
+
+	if moraleProfileFor(c.Type).Vengeful {
+		// Мстительные идут прямо к игроку
+		c.CurrentState = "chase"
+		return
+	}
+
+	// Осторожные держатся на расстоянии и выжидают
+	c.CurrentState = "stalk"
+	c.StalkingTime = 0
+}
@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"math"
+
+	"nightmare/internal/common"
+)
+
+// Параметры восприятия игрока
+const (
+	ViewFOV            = math.Pi * 2 / 3 // Угол обзора игрока (120 градусов)
+	ViewDistance       = 20.0            // Дальность обзора в тайлах
+	FlashlightAngle    = math.Pi / 3     // Угол конуса фонарика (60 градусов)
+	FlashlightRange    = 15.0            // Дальность луча фонарика
+	ObservedLightLevel = 0.35            // Минимальная освещенность, при которой объект "виден"
+)
+
+// Terrain предоставляет запросы к проходимости и видимости мира.
+// Реализуется world.CollisionSystem; интерфейс нужен, чтобы не импортировать world.
+type Terrain interface {
+	CheckCollision(position common.Vector2D) bool
+	CheckLineOfSight(from, to common.Vector2D) bool
+}
+
+// LightSampler возвращает уровень освещенности (от 0 до 1) в точке мира
+type LightSampler interface {
+	LightLevelAt(position common.Vector2D) float64
+}
+
+// ViewFrustum описывает конус обзора
+type ViewFrustum struct {
+	Origin    Vector2D
+	Direction float64 // Направление взгляда в радианах
+	FOV       float64 // Полный угол конуса в радианах
+	Range     float64
+}
+
+// ViewFrustum возвращает конус обзора игрока
+func (p *Player) ViewFrustum() ViewFrustum {
+	return ViewFrustum{
+		Origin:    p.Position,
+		Direction: p.Direction,
+		FOV:       ViewFOV,
+		Range:     ViewDistance,
+	}
+}
+
+// FlashlightFrustum возвращает конус луча фонарика игрока
+func (p *Player) FlashlightFrustum() ViewFrustum {
+	return ViewFrustum{
+		Origin:    p.Position,
+		Direction: p.Direction,
+		FOV:       FlashlightAngle,
+		Range:     FlashlightRange,
+	}
+}
+
+// Contains проверяет, попадает ли точка в конус (без учета препятствий)
+func (f ViewFrustum) Contains(point Vector2D) bool {
+	dx := point.X - f.Origin.X
+	dy := point.Y - f.Origin.Y
+	dist := math.Sqrt(dx*dx + dy*dy)
+
+	if dist > f.Range {
+		return false
+	}
+
+	// Точка в самом начале конуса всегда видна
+	if dist == 0 {
+		return true
+	}
+
+	return math.Abs(angleDifference(math.Atan2(dy, dx), f.Direction)) <= f.FOV/2
+}
+
+// CanSee проверяет, видит ли игрок точку с учетом препятствий
+func (p *Player) CanSee(point Vector2D, terrain Terrain) bool {
+	if !p.ViewFrustum().Contains(point) {
+		return false
+	}
+
+	if terrain == nil {
+		return true
+	}
+
+	return terrain.CheckLineOfSight(p.Position.ToCommonVector(), point.ToCommonVector())
+}
+
+// IsObserving проверяет, что точка в поле зрения игрока и освещена.
+// Без источника освещения освещенной считается область луча фонарика.
+func (p *Player) IsObserving(point Vector2D, terrain Terrain, light LightSampler) bool {
+	if !p.CanSee(point, terrain) {
+		return false
+	}
+
+	if light != nil {
+		return light.LightLevelAt(point.ToCommonVector()) >= ObservedLightLevel
+	}
+
+	return p.FlashlightFrustum().Contains(point)
+}
+
+// angleDifference возвращает разницу углов в пределах [-π, π]
+func angleDifference(a, b float64) float64 {
+	diff := a - b
+	for diff > math.Pi {
+		diff -= 2 * math.Pi
+	}
+	for diff < -math.Pi {
+		diff += 2 * math.Pi
+	}
+	return diff
+}
@@ -23,6 +23,7 @@ const (
 	ActionInteract
 	ActionRun
 	ActionHide
+	ActionAttack
 )
 
 // PlayerActionRecord records player actions with a timestamp
@@ -110,12 +111,16 @@ func (p *Player) TurnRight() {
 	}
 }
 
-// Interact interacts with the world
+// Interact records an interaction; picking items up is handled by the game
 func (p *Player) Interact(w interface{}) {
-	// Logic for interacting with world objects will go here
 	p.recordAction(ActionInteract)
 }
 
+// Attack records a melee attack; hits are resolved by the game's combat system
+func (p *Player) Attack() {
+	p.recordAction(ActionAttack)
+}
+
 // TakeDamage damages the player
 func (p *Player) TakeDamage(amount float64) {
 	p.Health -= amount
@@ -137,11 +142,30 @@ func (p *Player) AddItem(item Item) {
 	p.Inventory = append(p.Inventory, item)
 }
 
+// RecentActions counts actions of the given type within the time window
+func (p *Player) RecentActions(action PlayerAction, window time.Duration) int {
+	since := common.Now().Add(-window)
+	count := 0
+
+	// The log is ordered by time, so walk it from the end
+	for i := len(p.ActionLog) - 1; i >= 0; i-- {
+		record := p.ActionLog[i]
+		if record.Timestamp.Before(since) {
+			break
+		}
+		if record.Action == action {
+			count++
+		}
+	}
+
+	return count
+}
+
 // recordAction records a player action in the log
 func (p *Player) recordAction(action PlayerAction) {
 	record := PlayerActionRecord{
 		Action:    action,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 		Position:  p.Position,
 	}
 	p.ActionLog = append(p.ActionLog, record)
@@ -163,6 +187,8 @@ func ConvertToCommonAction(action PlayerAction) common.PlayerActionType {
 		return common.ActionRun
 	case ActionHide:
 		return common.ActionHide
+	case ActionAttack:
+		return common.ActionAttack
 	default:
 		return common.ActionMove
 	}
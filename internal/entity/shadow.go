@@ -0,0 +1,136 @@
+package entity
+
+import (
+	"math"
+
+	"nightmare/internal/common"
+)
+
+// Параметры поведения тени
+const (
+	ShadowLightThreshold = 0.3  // Освещенность, выше которой тень не может находиться
+	shadowBurnRate       = 0.04 // Сколько сущности тень теряет за кадр на полном свету
+	shadowRegenRate      = 0.01 // Скорость восстановления сущности в темноте
+	shadowPushback       = 1.5  // Во сколько раз быстрее обычного тень отступает от света
+	shadowReformTime     = 300  // Через сколько кадров растворившаяся тень соберется снова
+	shadowReformTries    = 12   // Количество попыток найти темный тайл для восстановления
+	shadowReformRadius   = 20.0 // Радиус поиска темного тайла
+)
+
+// updateShadow обрабатывает взаимодействие тени со светом.
+// Возвращает true, если тень в этом кадре занята светом и обычное поведение пропускается.
+func (c *Creature) updateShadow(bounds common.Bounds) bool {
+	if c.CurrentState == "dissolved" {
+		c.updateDissolved(bounds)
+		return true
+	}
+
+	level := c.lightLevel(c.Position)
+	if level < ShadowLightThreshold {
+		// В темноте тень восстанавливается
+		c.Essence = math.Min(1.0, c.Essence+shadowRegenRate)
+		return false
+	}
+
+	// Свет выжигает тень
+	c.Essence -= level * shadowBurnRate
+	if c.Essence <= 0 {
+		c.Dissolve()
+		return true
+	}
+
+	// И отбрасывает ее прочь от источника
+	if c.PlayerTarget != nil {
+		c.Direction = c.getDirectionTo(c.PlayerTarget.Position) + math.Pi
+	} else {
+		c.Direction += math.Pi
+	}
+	// Как и отброс от удара, свет не загоняет тень в препятствия
+	c.Knockback(c.Direction, c.Speed*shadowPushback)
+
+	return true
+}
+
+// Dissolve растворяет тень; она соберется снова в темноте после перезарядки
+func (c *Creature) Dissolve() {
+	c.Essence = 0
+	c.IsVisible = false
+	c.CurrentState = "dissolved"
+	c.StateTime = 0
+}
+
+// IsDissolved проверяет, растворена ли тень
+func (c *Creature) IsDissolved() bool {
+	return c.CurrentState == "dissolved"
+}
+
+// updateDissolved ждет окончания перезарядки и собирает тень в темном месте
+func (c *Creature) updateDissolved(bounds common.Bounds) {
+	if c.StateTime < shadowReformTime {
+		return
+	}
+
+	pos, ok := c.findDarkPosition(bounds)
+	if !ok {
+		// Вокруг слишком светло, пробуем позже
+		c.StateTime = shadowReformTime / 2
+		return
+	}
+
+	c.Position = pos
+	c.Essence = 1.0
+	c.CurrentState = "idle"
+	if c.PlayerTarget != nil {
+		c.CurrentState = "stalk"
+	}
+	c.StateTime = 0
+}
+
+// findDarkPosition ищет неосвещенный проходимый тайл рядом с тенью
+func (c *Creature) findDarkPosition(bounds common.Bounds) (Vector2D, bool) {
+	for i := 0; i < shadowReformTries; i++ {
+		angle := common.Rand().Float64() * 2 * math.Pi
+		dist := common.Rand().Float64() * shadowReformRadius
+		pos := Vector2D{
+			X: c.Position.X + math.Cos(angle)*dist,
+			Y: c.Position.Y + math.Sin(angle)*dist,
+		}
+
+		if !bounds.Contains(pos.ToCommonVector()) {
+			continue
+		}
+
+		if c.Terrain != nil && c.Terrain.CheckCollision(pos.ToCommonVector()) {
+			continue
+		}
+
+		if c.lightLevel(pos) < ShadowLightThreshold {
+			return pos, true
+		}
+	}
+
+	return Vector2D{}, false
+}
+
+// canEnter проверяет, может ли существо переместиться в точку
+func (c *Creature) canEnter(pos Vector2D) bool {
+	// Тень не может выйти на освещенный тайл
+	if c.Type == "shadow" && c.lightLevel(pos) >= ShadowLightThreshold {
+		return false
+	}
+	return true
+}
+
+// lightLevel возвращает освещенность точки.
+// Без системы освещения светом считается луч фонарика преследуемого игрока.
+func (c *Creature) lightLevel(pos Vector2D) float64 {
+	if c.Light != nil {
+		return c.Light.LightLevelAt(pos.ToCommonVector())
+	}
+
+	if c.PlayerTarget != nil && c.PlayerTarget.FlashlightFrustum().Contains(pos) {
+		return 1.0
+	}
+
+	return 0
+}
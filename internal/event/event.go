@@ -3,6 +3,8 @@ package event
 import (
 	"sync"
 	"time"
+
+	"nightmare/internal/common"
 )
 
 // EventType представляет тип события
@@ -121,7 +123,7 @@ func (em *EventManager) Trigger(eventType EventType, source, target, position, v
 		Target:    target,
 		Position:  position,
 		Value:     value,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 		Custom:    make(map[string]interface{}),
 	}
 
@@ -136,10 +138,13 @@ func (em *EventManager) TriggerCustom(eventName string, source, target, position
 		Target:    target,
 		Position:  position,
 		Value:     value,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 		Custom:    customData,
 	}
 
+	if data.Custom == nil {
+		data.Custom = make(map[string]interface{})
+	}
 	data.Custom["name"] = eventName
 
 	em.TriggerWithData(data)
@@ -195,7 +200,7 @@ func NewScareEvent(scareType, source, position interface{}, intensity float64) E
 		Source:    source,
 		Position:  position,
 		Value:     intensity,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 		Custom: map[string]interface{}{
 			"scareType": scareType,
 		},
@@ -208,7 +213,7 @@ func NewPlayerMovedEvent(player, oldPosition, newPosition interface{}) EventData
 		Type:      EventPlayerMoved,
 		Source:    player,
 		Position:  newPosition,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 		Custom: map[string]interface{}{
 			"oldPosition": oldPosition,
 		},
@@ -222,7 +227,7 @@ func NewPlayerDamagedEvent(player, source interface{}, amount float64) EventData
 		Source:    source,
 		Target:    player,
 		Value:     amount,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 	}
 }
 
@@ -233,7 +238,7 @@ func NewPlayerInteractedEvent(player, target, position interface{}) EventData {
 		Source:    player,
 		Target:    target,
 		Position:  position,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 	}
 }
 
@@ -243,7 +248,7 @@ func NewPlayerSanityChangedEvent(player interface{}, oldValue, newValue float64)
 		Type:      EventPlayerSanityChanged,
 		Source:    player,
 		Value:     newValue - oldValue,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 		Custom: map[string]interface{}{
 			"oldValue": oldValue,
 			"newValue": newValue,
@@ -257,7 +262,7 @@ func NewCreatureSpawnedEvent(creature, position interface{}) EventData {
 		Type:      EventCreatureSpawned,
 		Source:    creature,
 		Position:  position,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 	}
 }
 
@@ -268,7 +273,7 @@ func NewCreatureKilledEvent(creature, killer, position interface{}) EventData {
 		Source:    killer,
 		Target:    creature,
 		Position:  position,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 	}
 }
 
@@ -279,7 +284,7 @@ func NewCreatureDetectedEvent(creature, detector, position interface{}) EventDat
 		Source:    detector,
 		Target:    creature,
 		Position:  position,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 	}
 }
 
@@ -290,7 +295,7 @@ func NewWorldChangedEvent(source, position interface{}, radius float64) EventDat
 		Source:    source,
 		Position:  position,
 		Value:     radius,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 	}
 }
 
@@ -301,7 +306,7 @@ func NewItemPickedUpEvent(player, item, position interface{}) EventData {
 		Source:    player,
 		Target:    item,
 		Position:  position,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 	}
 }
 
@@ -312,7 +317,7 @@ func NewItemUsedEvent(player, item, target, position interface{}) EventData {
 		Source:    player,
 		Target:    item,
 		Position:  position,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 		Custom: map[string]interface{}{
 			"itemTarget": target,
 		},
@@ -325,7 +330,7 @@ func NewAmbientChangedEvent(source, oldAmbient, newAmbient interface{}) EventDat
 		Type:      EventAmbientChanged,
 		Source:    source,
 		Target:    newAmbient,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 		Custom: map[string]interface{}{
 			"oldAmbient": oldAmbient,
 		},
@@ -338,7 +343,7 @@ func NewGameStateChangedEvent(source, oldState, newState interface{}) EventData
 		Type:      EventGameStateChanged,
 		Source:    source,
 		Value:     newState,
-		Timestamp: time.Now(),
+		Timestamp: common.Now(),
 		Custom: map[string]interface{}{
 			"oldState": oldState,
 		},
@@ -400,6 +400,16 @@ func (f *ItemFactory) CreateItem(templateID string) *Item {
 	return item
 }
 
+// CreateItemByName создает предмет по шаблону с указанным названием
+func (f *ItemFactory) CreateItemByName(name string) *Item {
+	for templateID, template := range f.itemsDB {
+		if template.Name == name {
+			return f.CreateItem(templateID)
+		}
+	}
+	return nil
+}
+
 // CreateRandomItem создает случайный предмет указанного типа и редкости
 func (f *ItemFactory) CreateRandomItem(itemType ItemType, rarity ItemRarity) *Item {
 	// Собираем все шаблоны указанного типа и редкости
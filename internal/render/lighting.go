@@ -52,6 +52,7 @@ type LightingSystem struct {
 	shadowQuality   int     // Качество теней (количество лучей)
 	globalTime      float64 // Глобальное время для анимаций
 	collisionSystem *world.CollisionSystem
+	quiet           bool // Тихое окно: свет горит ровно, без мерцания
 }
 
 // NewLightingSystem создает новую систему освещения
@@ -111,6 +112,16 @@ func (ls *LightingSystem) SetAmbientLight(ambient color.RGBA) {
 	ls.ambientLight = ambient
 }
 
+// SetQuiet включает или выключает тихое окно
+func (ls *LightingSystem) SetQuiet(quiet bool) {
+	ls.quiet = quiet
+}
+
+// AmbientLight возвращает фоновое освещение
+func (ls *LightingSystem) AmbientLight() color.RGBA {
+	return ls.ambientLight
+}
+
 // Update обновляет состояние освещения
 func (ls *LightingSystem) Update(deltaTime float64) {
 	// Обновляем глобальное время
@@ -118,7 +129,7 @@ func (ls *LightingSystem) Update(deltaTime float64) {
 
 	// Обновляем источники света (мерцание и т.д.)
 	for _, light := range ls.lights {
-		if light.Flicker > 0 {
+		if light.Flicker > 0 && !ls.quiet {
 			// Вычисляем мерцание
 			flickerTime := ls.globalTime*light.FlickerSpeed + light.TimeOffset
 			flickerValue := math.Sin(flickerTime) * light.Flicker
@@ -132,6 +143,57 @@ func (ls *LightingSystem) Update(deltaTime float64) {
 	ls.updateOcclusionMap()
 }
 
+// LightLevelAt возвращает освещенность (от 0 до 1) в точке мира.
+// Используется игровой логикой, поэтому считается аналитически, без карты освещения.
+func (ls *LightingSystem) LightLevelAt(position common.Vector2D) float64 {
+	// Фоновый свет
+	level := (float64(ls.ambientLight.R) + float64(ls.ambientLight.G) + float64(ls.ambientLight.B)) / (3 * 255)
+
+	point := entity.FromCommonVector(position)
+
+	for _, light := range ls.lights {
+		if !light.IsActive {
+			continue
+		}
+
+		switch light.Type {
+		case LightAmbient, LightDirectional:
+			level += light.Intensity
+			continue
+		}
+
+		dx := point.X - light.Position.X
+		dy := point.Y - light.Position.Y
+		distance := math.Sqrt(dx*dx + dy*dy)
+		if distance > light.Radius {
+			continue
+		}
+
+		// Затухание с расстоянием, как при отрисовке
+		intensity := (1.0 - math.Pow(distance/light.Radius, light.Falloff)) * light.Intensity
+
+		// Для прожектора учитываем конус
+		if light.Type == LightSpot && distance > 0 {
+			angle := math.Atan2(light.Direction.Y, light.Direction.X)
+			angleDiff := math.Abs(normalizeAngle(math.Atan2(dy, dx) - angle))
+			if angleDiff > light.Angle/2 {
+				continue
+			}
+			intensity *= 1.0 - angleDiff/(light.Angle/2)
+		}
+
+		// Препятствия отбрасывают тень
+		if light.CastShadows && ls.collisionSystem != nil &&
+			!ls.collisionSystem.CheckLineOfSight(light.Position.ToCommonVector(), position) {
+			continue
+		}
+
+		level += clampFloat64(intensity, 0, 1)
+	}
+
+	return clampFloat64(level, 0, 1)
+}
+
 // updateOcclusionMap обновляет карту преград для света
 func (ls *LightingSystem) updateOcclusionMap() {
 	// Если нет системы коллизий, просто выходим
@@ -117,24 +117,17 @@ func (r *Renderer) DrawWorld(screen *ebiten.Image, w *world.World, player *entit
 	r.viewOffsetY = player.Position.Y
 
 	// Определяем видимый диапазон тайлов
-	startX := int(r.viewOffsetX) - ViewRadius
-	endX := int(r.viewOffsetX) + ViewRadius
-	startY := int(r.viewOffsetY) - ViewRadius
-	endY := int(r.viewOffsetY) + ViewRadius
-
-	// Ограничиваем диапазон тайлов размерами мира
-	if startX < 0 {
-		startX = 0
-	}
-	if startY < 0 {
-		startY = 0
-	}
-	if endX >= w.Width {
-		endX = w.Width - 1
-	}
-	if endY >= w.Height {
-		endY = w.Height - 1
-	}
+	startX := int(math.Floor(r.viewOffsetX)) - ViewRadius
+	endX := int(math.Floor(r.viewOffsetX)) + ViewRadius
+	startY := int(math.Floor(r.viewOffsetY)) - ViewRadius
+	endY := int(math.Floor(r.viewOffsetY)) + ViewRadius
+
+	// Ограничиваем диапазон тайлов границами мира (в бесконечном мире - загруженными чанками)
+	bounds := w.Bounds()
+	startX = max(startX, int(bounds.Min.X))
+	startY = max(startY, int(bounds.Min.Y))
+	endX = min(endX, int(bounds.Max.X)-1)
+	endY = min(endY, int(bounds.Max.Y)-1)
 
 	// Отрисовываем видимые тайлы
 	for y := startY; y <= endY; y++ {
@@ -187,6 +180,10 @@ func (r *Renderer) drawObject(screen *ebiten.Image, obj common.WorldObject, x, y
 	case "rock":
 		ebitenutil.DrawRect(screen, float64(x+TileSize/3), float64(y+TileSize/3),
 			TileSize/3, TileSize/3, color.RGBA{100, 100, 100, 255})
+	case "item":
+		// Предмет лежит на земле, его можно подобрать
+		ebitenutil.DrawRect(screen, float64(x+TileSize*3/8), float64(y+TileSize*3/8),
+			TileSize/4, TileSize/4, color.RGBA{200, 170, 60, 255})
 	}
 }
 
@@ -208,11 +205,84 @@ func (r *Renderer) DrawEntities(screen *ebiten.Image, entities []*world.Entity,
 	}
 }
 
+// DrawHallucinations отрисовывает то, что видит только игрок
+func (r *Renderer) DrawHallucinations(screen *ebiten.Image, hallucinations []*world.Hallucination) {
+	for _, h := range hallucinations {
+		// Галлюцинации мерцают
+		if h.Age%12 < 3 {
+			continue
+		}
+
+		if h.Kind == world.HallucinationPath {
+			for i, step := range h.Path {
+				x, y := r.worldToScreen(step)
+				// Следы появляются один за другим
+				if i*5 > h.Age {
+					break
+				}
+				ebitenutil.DrawRect(screen, x-2, y-1, 4, 3, color.RGBA{60, 40, 30, 160})
+			}
+			continue
+		}
+
+		x, y := r.worldToScreen(h.Position)
+		if x+TileSize < 0 || y+TileSize < 0 || x >= float64(r.screenWidth) || y >= float64(r.screenHeight) {
+			continue
+		}
+
+		switch h.Kind {
+		case world.HallucinationCreature:
+			// Полупрозрачный силуэт с горящими глазами
+			ebitenutil.DrawRect(screen, x-TileSize/4, y-TileSize/2, TileSize/2, TileSize, color.RGBA{30, 0, 30, 110})
+			ebitenutil.DrawRect(screen, x-TileSize/6, y-TileSize/3, 2, 2, color.RGBA{255, 40, 40, 200})
+			ebitenutil.DrawRect(screen, x+TileSize/6-2, y-TileSize/3, 2, 2, color.RGBA{255, 40, 40, 200})
+
+		case world.HallucinationObject:
+			ebitenutil.DrawRect(screen, x-TileSize/4, y-TileSize/4, TileSize/2, TileSize/2, color.RGBA{140, 120, 100, 120})
+
+		case world.HallucinationLight:
+			// Далекий фонарь, свет которого ничего не освещает
+			glow := uint8(40 + 20*math.Sin(float64(h.Age)*0.1))
+			ebitenutil.DrawRect(screen, x-TileSize, y-TileSize, TileSize*2, TileSize*2, color.RGBA{255, 200, 120, glow})
+			ebitenutil.DrawRect(screen, x-2, y-2, 4, 4, color.RGBA{255, 230, 160, 220})
+		}
+	}
+}
+
+// worldToScreen переводит координаты мира в координаты центра тайла на экране
+func (r *Renderer) worldToScreen(position common.Vector2D) (float64, float64) {
+	x := (position.X-r.viewOffsetX)*TileSize + float64(r.screenWidth)/2 + TileSize/2
+	y := (position.Y-r.viewOffsetY)*TileSize + float64(r.screenHeight)/2 + TileSize/2
+	return x, y
+}
+
 // drawEntity отрисовывает сущность
 func (r *Renderer) drawEntity(screen *ebiten.Image, entity *world.Entity, x, y int) {
 	// В реальном проекте здесь будет отрисовка сущности
 	// В этом примере мы просто нарисуем цветной прямоугольник
 
+	// Растворенная светом тень невидима, пока не соберется снова; мертвые не рисуются
+	if entity.Creature != nil && (entity.Creature.IsDissolved() || entity.Creature.IsDead()) {
+		return
+	}
+
+	// Замаскированный двойник выглядит в точности как игрок
+	if entity.Creature != nil && entity.Creature.Disguised {
+		centerX := float64(x + TileSize/2)
+		centerY := float64(y + TileSize/2)
+
+		// Пятно света от фонаря впереди фигуры
+		if entity.Creature.CarriesLight {
+			lightX := centerX + math.Cos(entity.Direction)*TileSize*1.5
+			lightY := centerY + math.Sin(entity.Direction)*TileSize*1.5
+			ebitenutil.DrawRect(screen, lightX-TileSize, lightY-TileSize,
+				TileSize*2, TileSize*2, color.RGBA{255, 240, 180, 40})
+		}
+
+		r.drawPlayerFigure(screen, centerX, centerY, entity.Direction)
+		return
+	}
+
 	switch entity.Type {
 	case "shadow":
 		ebitenutil.DrawRect(screen, float64(x), float64(y),
@@ -224,6 +294,44 @@ func (r *Renderer) drawEntity(screen *ebiten.Image, entity *world.Entity, x, y i
 		ebitenutil.DrawRect(screen, float64(x+TileSize/4), float64(y+TileSize/4),
 			TileSize/2, TileSize/2, color.RGBA{255, 0, 0, 255})
 	}
+	// Части тела с текущим кадром анимации
+	if entity.Creature != nil {
+		r.drawCreatureParts(screen, entity.Creature, float64(x+TileSize/2), float64(y+TileSize/2))
+	}
+}
+
+// drawCreatureParts отрисовывает части тела существа с учетом кадров анимации
+// и процедурного движения. Кадр пока передается только яркостью части.
+func (r *Renderer) drawCreatureParts(screen *ebiten.Image, creature *entity.Creature, centerX, centerY float64) {
+	cos := math.Cos(creature.Direction)
+	sin := math.Sin(creature.Direction)
+
+	blendFrame, blendWeight := creature.Animator.BlendFrame()
+
+	for _, part := range creature.Parts {
+		// Смещение части в локальных координатах существа, повернутое по направлению
+		localX := part.Position.X + part.MotionOffset.X
+		localY := part.Position.Y + part.MotionOffset.Y
+		px := centerX + (localX*cos-localY*sin)*TileSize/2
+		py := centerY + (localX*sin+localY*cos)*TileSize/2
+
+		// Яркость зависит от кадра, при переходе смешивается с предыдущим клипом
+		shade := frameShade(part.CurrentAnim)*(1-blendWeight) + frameShade(blendFrame)*blendWeight
+		c := uint8(60 + 120*shade)
+
+		size := TileSize / 6 * part.Scale
+		ebitenutil.DrawRect(screen, px-size/2, py-size/2, size, size, color.RGBA{c, c / 3, c / 3, 220})
+
+		// Поворот части показываем отрезком
+		angle := creature.Direction + part.Rotation + part.MotionRotation
+		ebitenutil.DrawLine(screen, px, py, px+math.Cos(angle)*size, py+math.Sin(angle)*size,
+			color.RGBA{c, c, c, 200})
+	}
+}
+
+// frameShade возвращает яркость (от 0 до 1) для кадра анимации
+func frameShade(frame int) float64 {
+	return 0.5 + 0.5*math.Sin(float64(frame)*0.9)
 }
 
 // DrawPlayer отрисовывает игрока
@@ -232,14 +340,19 @@ func (r *Renderer) DrawPlayer(screen *ebiten.Image, player *entity.Player) {
 	x := r.screenWidth / 2
 	y := r.screenHeight / 2
 
+	r.drawPlayerFigure(screen, float64(x), float64(y), player.Direction)
+}
+
+// drawPlayerFigure отрисовывает фигуру игрока с центром в точке (x, y)
+func (r *Renderer) drawPlayerFigure(screen *ebiten.Image, x, y, direction float64) {
 	// Отрисовываем игрока
-	ebitenutil.DrawRect(screen, float64(x-TileSize/2), float64(y-TileSize/2),
+	ebitenutil.DrawRect(screen, x-TileSize/2, y-TileSize/2,
 		TileSize, TileSize, color.RGBA{255, 255, 0, 255})
 
 	// Отрисовываем направление игрока
-	endX := float64(x) + math.Cos(player.Direction)*TileSize
-	endY := float64(y) + math.Sin(player.Direction)*TileSize
-	ebitenutil.DrawLine(screen, float64(x), float64(y), endX, endY, color.RGBA{255, 0, 0, 255})
+	endX := x + math.Cos(direction)*TileSize
+	endY := y + math.Sin(direction)*TileSize
+	ebitenutil.DrawLine(screen, x, y, endX, endY, color.RGBA{255, 0, 0, 255})
 }
 
 // DrawUI отрисовывает пользовательский интерфейс
@@ -257,6 +370,65 @@ func (r *Renderer) DrawUI(screen *ebiten.Image, player *entity.Player) {
 	ebitenutil.DebugPrintAt(screen, "Sanity", 25, 52)
 }
 
+// Размеры отладочной панели
+const (
+	debugPanelWidth  = 250 // Ширина панели в правом верхнем углу
+	debugGraphHeight = 60  // Высота графика
+	debugLineHeight  = 14  // Высота строки текста
+	debugLogLines    = 8   // Строк журнала внизу экрана
+)
+
+// debugBackground - полупрозрачная подложка отладочной информации
+var debugBackground = color.RGBA{0, 0, 0, 160}
+
+// DrawDebugGraph отрисовывает графики значений от 0 до 1 в правом верхнем углу
+func (r *Renderer) DrawDebugGraph(screen *ebiten.Image, series [][]float64, colors []color.RGBA) {
+	x := float64(r.screenWidth - debugPanelWidth)
+	y := 20.0
+	width := float64(debugPanelWidth - 20)
+	height := float64(debugGraphHeight)
+
+	ebitenutil.DrawRect(screen, x, y, width, height, debugBackground)
+
+	for i, values := range series {
+		if len(values) < 2 {
+			continue
+		}
+
+		step := width / float64(len(values)-1)
+		for j := 1; j < len(values); j++ {
+			ebitenutil.DrawLine(screen,
+				x+float64(j-1)*step, y+height*(1-values[j-1]),
+				x+float64(j)*step, y+height*(1-values[j]),
+				colors[i%len(colors)])
+		}
+	}
+}
+
+// DrawDebugInfo отрисовывает отладочную информацию в правом верхнем углу, под графиком
+func (r *Renderer) DrawDebugInfo(screen *ebiten.Image, lines []string) {
+	x := r.screenWidth - debugPanelWidth
+	y := 30 + debugGraphHeight
+
+	ebitenutil.DrawRect(screen, float64(x), float64(y), float64(debugPanelWidth-20), float64(len(lines)*debugLineHeight+4), debugBackground)
+	for i, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, x+4, y+i*debugLineHeight)
+	}
+}
+
+// DrawDebugLog отрисовывает последние строки журнала внизу экрана
+func (r *Renderer) DrawDebugLog(screen *ebiten.Image, lines []string) {
+	if len(lines) > debugLogLines {
+		lines = lines[len(lines)-debugLogLines:]
+	}
+
+	y := r.screenHeight - debugLogLines*debugLineHeight - 10
+	ebitenutil.DrawRect(screen, 10, float64(y), float64(r.screenWidth-20), float64(debugLogLines*debugLineHeight+4), debugBackground)
+	for i, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, 14, y+i*debugLineHeight)
+	}
+}
+
 // DrawMainMenu отрисовывает главное меню
 func (r *Renderer) DrawMainMenu(screen *ebiten.Image) {
 	// Отрисовываем фон
@@ -268,6 +440,8 @@ func (r *Renderer) DrawMainMenu(screen *ebiten.Image) {
 	// Отрисовываем инструкции
 	ebitenutil.DebugPrintAt(screen, "Press ENTER to start", r.screenWidth/2-70, r.screenHeight/2)
 	ebitenutil.DebugPrintAt(screen, "WASD - move, ESC - pause", r.screenWidth/2-90, r.screenHeight/2+30)
+	ebitenutil.DebugPrintAt(screen, "E - interact, SPACE - attack", r.screenWidth/2-90, r.screenHeight/2+50)
+	ebitenutil.DebugPrintAt(screen, "Q - drop held item", r.screenWidth/2-90, r.screenHeight/2+70)
 }
 
 // DrawPauseMenu отрисовывает меню паузы
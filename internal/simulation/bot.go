@@ -0,0 +1,161 @@
+package simulation
+
+import (
+	"math"
+	"math/rand"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+const (
+	edgeMargin       = 20.0 // Bots turn back toward the center this close to the world edge
+	methodicalLeg    = 600  // Ticks a methodical bot walks before turning a corner
+	headingTolerance = 0.1  // Radians within which a bot counts as facing its target
+)
+
+// botState is what a bot is doing right now
+type botState int
+
+const (
+	stateWalk botState = iota
+	statePause
+	stateReact
+)
+
+// Bot plays the game through the same player controls a human uses
+type Bot struct {
+	player  *entity.Player
+	persona Persona
+	random  *rand.Rand
+
+	state     botState
+	stateLeft int     // Ticks until the current pause or reaction ends
+	heading   float64 // Direction the bot wants to face
+	tick      int
+
+	worldWidth, worldHeight float64
+}
+
+// newBot creates a bot that controls the player
+func newBot(player *entity.Player, persona Persona, random *rand.Rand, width, height int) *Bot {
+	return &Bot{
+		player:      player,
+		persona:     persona,
+		random:      random,
+		heading:     player.Direction,
+		worldWidth:  float64(width),
+		worldHeight: float64(height),
+	}
+}
+
+// Scared makes the bot react to a scare at the position
+func (b *Bot) Scared(position common.Vector2D) {
+	b.state = stateReact
+	b.stateLeft = b.persona.ReactionTicks
+
+	toScare := math.Atan2(position.Y-b.player.Position.Y, position.X-b.player.Position.X)
+	switch b.persona.Reaction {
+	case ReactionFlee:
+		b.heading = toScare + math.Pi
+	case ReactionInvestigate:
+		b.heading = toScare
+	}
+}
+
+// Update plays one tick
+func (b *Bot) Update() {
+	b.tick++
+
+	switch b.state {
+	case statePause:
+		b.countDown()
+		return
+
+	case stateReact:
+		b.countDown()
+		if b.persona.Reaction == ReactionFreeze {
+			return
+		}
+		// Fleeing and investigating bots hurry: twice their usual pace
+		b.steer()
+		b.step(max(1, b.persona.MoveEvery/2))
+		if b.persona.Reaction == ReactionFlee && b.random.Float64() < b.persona.TurnJitter {
+			b.heading += (b.random.Float64() - 0.5) * math.Pi / 2
+		}
+		return
+	}
+
+	b.wander()
+	b.steer()
+	b.step(b.persona.MoveEvery)
+
+	if b.random.Float64() < b.persona.InteractChance {
+		b.player.Interact(nil)
+	}
+	if b.random.Float64() < b.persona.AttackChance {
+		b.player.Attack()
+	}
+}
+
+// wander picks where to go next while nothing is happening
+func (b *Bot) wander() {
+	persona := b.persona
+
+	switch {
+	case b.random.Float64() < persona.PauseChance:
+		b.state = statePause
+		b.stateLeft = persona.PauseTicks
+	case b.random.Float64() < persona.ReverseChance:
+		b.heading += math.Pi
+	case b.random.Float64() < persona.TurnJitter:
+		b.heading += (b.random.Float64() - 0.5) * math.Pi / 2
+	}
+
+	// Methodical players sweep the forest in a square pattern
+	if persona.TurnJitter == 0 && b.tick%methodicalLeg == 0 {
+		b.heading += math.Pi / 2
+	}
+
+	// Nobody walks off the edge of the world
+	position := b.player.Position
+	if position.X < edgeMargin || position.Y < edgeMargin ||
+		position.X > b.worldWidth-edgeMargin || position.Y > b.worldHeight-edgeMargin {
+		b.heading = math.Atan2(b.worldHeight/2-position.Y, b.worldWidth/2-position.X)
+	}
+}
+
+// steer turns the player toward the wanted heading one control press at a time
+func (b *Bot) steer() {
+	diff := math.Remainder(b.heading-b.player.Direction, 2*math.Pi)
+	switch {
+	case diff > headingTolerance:
+		b.player.TurnRight()
+	case diff < -headingTolerance:
+		b.player.TurnLeft()
+	}
+}
+
+// step moves the player forward every few ticks, if the player is facing roughly the right way
+func (b *Bot) step(every int) {
+	if b.tick%every != 0 {
+		return
+	}
+	if math.Abs(math.Remainder(b.heading-b.player.Direction, 2*math.Pi)) > math.Pi/4 {
+		return
+	}
+
+	b.player.MoveForward()
+
+	// The world has no walls at its edge, so the bot stays inside it
+	b.player.Position.X = math.Max(0, math.Min(b.worldWidth-1, b.player.Position.X))
+	b.player.Position.Y = math.Max(0, math.Min(b.worldHeight-1, b.player.Position.Y))
+}
+
+// countDown ends a pause or a reaction when its time runs out
+func (b *Bot) countDown() {
+	b.stateLeft--
+	if b.stateLeft <= 0 {
+		b.state = stateWalk
+	}
+}
@@ -0,0 +1,96 @@
+package simulation
+
+import (
+	"fmt"
+	"time"
+
+	"nightmare/internal/ai"
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+	"nightmare/internal/world"
+)
+
+// Violation is a fairness guarantee broken during a run
+type Violation struct {
+	Tick   int
+	Rule   string
+	Detail string
+}
+
+// String describes the violation
+func (v Violation) String() string {
+	return fmt.Sprintf("tick %d: %s, %s", v.Tick, v.Rule, v.Detail)
+}
+
+// fairnessChecker watches every creature that appears during a run, independently of the
+// director and the population manager, and records each fairness guarantee that was broken
+type fairnessChecker struct {
+	rules      ai.FairnessRules
+	world      *world.World
+	player     *entity.Player
+	graceUntil time.Time
+	seen       map[int]bool
+	lastDamage time.Time
+	violations []Violation
+}
+
+// newFairnessChecker starts checking; creatures already in the world are not counted
+func newFairnessChecker(rules ai.FairnessRules, w *world.World, player *entity.Player) *fairnessChecker {
+	checker := &fairnessChecker{
+		rules:      rules,
+		world:      w,
+		player:     player,
+		graceUntil: common.Now().Add(rules.Grace),
+		seen:       make(map[int]bool),
+	}
+	for _, e := range w.Entities {
+		checker.seen[e.ID] = true
+	}
+	return checker
+}
+
+// checkSpawns looks at the creatures that appeared since the previous tick
+func (c *fairnessChecker) checkSpawns(tick int) {
+	origin := c.player.Position.ToCommonVector()
+
+	for _, e := range c.world.Entities {
+		if e.Creature == nil || c.seen[e.ID] {
+			continue
+		}
+		c.seen[e.ID] = true
+
+		where := fmt.Sprintf("%s at (%.0f, %.0f)", e.Type, e.Position.X, e.Position.Y)
+		if common.Now().Before(c.graceUntil) {
+			c.violate(tick, ai.RuleGrace, where+" during the grace period")
+		}
+		if dist := common.Distance(e.Position, origin); dist < c.rules.MinSpawnDistance {
+			c.violate(tick, ai.RuleSpawnDistance, fmt.Sprintf("%s %.1f tiles from the player", where, dist))
+		}
+		if c.rules.SafeZones && c.world.ZoneNameAt(e.Position) == "safe" {
+			c.violate(tick, ai.RuleSafeZone, where+" in a safe zone")
+		}
+		if c.rules.EscapeRoute && !c.world.HasEscapeRoute(e.Position) {
+			c.violate(tick, ai.RuleEscapeRoute, where+" cuts off every safe zone")
+		}
+	}
+}
+
+// checkScare looks at a scare the director played; creature appearances are the ones that hurt
+func (c *fairnessChecker) checkScare(tick int, scareType common.ScareEventType) {
+	if scareType != common.EventCreatureAppearance {
+		return
+	}
+
+	now := common.Now()
+	if c.player.Health < c.rules.CriticalHealth && !c.lastDamage.IsZero() &&
+		now.Sub(c.lastDamage) < c.rules.DamageScareCooldown {
+		c.violate(tick, ai.RuleCriticalHealth,
+			fmt.Sprintf("damage scare %.0fs after the previous one at %.0f health", now.Sub(c.lastDamage).Seconds(), c.player.Health))
+	}
+	c.lastDamage = now
+}
+
+// violate records a broken guarantee
+func (c *fairnessChecker) violate(tick int, rule, detail string) {
+	c.violations = append(c.violations, Violation{Tick: tick, Rule: rule, Detail: detail})
+}
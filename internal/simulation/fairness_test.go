@@ -0,0 +1,45 @@
+package simulation
+
+import "testing"
+
+// fairnessConfig returns a short run with a strict spawn distance, so that a director
+// ignoring the rules is sure to break them a few times
+func fairnessConfig() Config {
+	config := DefaultConfig()
+	config.Ticks = 12000
+	config.Fairness.MinSpawnDistance = 20
+	return config
+}
+
+func TestRunKeepsFairness(t *testing.T) {
+	config := fairnessConfig()
+
+	for _, persona := range Personas() {
+		report, err := Run(persona, config)
+		if err != nil {
+			t.Fatalf("%s: %v", persona.Name, err)
+		}
+		if !report.Fair() {
+			for _, violation := range report.Violations {
+				t.Errorf("%s: %v", persona.Name, violation)
+			}
+		}
+	}
+}
+
+func TestRunWithoutRulesBreaksFairness(t *testing.T) {
+	config := fairnessConfig()
+	config.Unfair = true
+
+	violations := 0
+	for _, persona := range Personas() {
+		report, err := Run(persona, config)
+		if err != nil {
+			t.Fatalf("%s: %v", persona.Name, err)
+		}
+		violations += len(report.Violations)
+	}
+	if violations == 0 {
+		t.Error("no violations with the fairness rules turned off")
+	}
+}
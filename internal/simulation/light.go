@@ -0,0 +1,56 @@
+package simulation
+
+import (
+	"math"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+	"nightmare/internal/world"
+)
+
+// Flashlight settings, as in the game
+const (
+	ambientLight  = 70.0 / (3 * 255) // Night ambient light of the game
+	beamIntensity = 0.8
+	beamRadius    = 15.0
+	beamAngle     = math.Pi / 3
+	beamFalloff   = 1.2
+)
+
+// flashlight lights the world like the game does: a faint night ambient
+// and the player's flashlight beam, which terrain blocks
+type flashlight struct {
+	world  *world.World
+	player *entity.Player
+}
+
+// newFlashlight gives the player a flashlight in the world
+func newFlashlight(w *world.World, player *entity.Player) *flashlight {
+	return &flashlight{world: w, player: player}
+}
+
+// LightLevelAt returns the light level (from 0 to 1) at the position
+func (f *flashlight) LightLevelAt(position common.Vector2D) float64 {
+	origin := f.player.Position.ToCommonVector()
+	dx := position.X - origin.X
+	dy := position.Y - origin.Y
+	distance := math.Hypot(dx, dy)
+	if distance > beamRadius {
+		return ambientLight
+	}
+
+	intensity := (1 - math.Pow(distance/beamRadius, beamFalloff)) * beamIntensity
+	if distance > 0 {
+		angleDiff := math.Abs(math.Remainder(math.Atan2(dy, dx)-f.player.Direction, 2*math.Pi))
+		if angleDiff > beamAngle/2 {
+			return ambientLight
+		}
+		intensity *= 1 - angleDiff/(beamAngle/2)
+	}
+
+	// Trees and rocks cast shadows in the beam
+	if !f.world.HasLineOfSight(origin, position) {
+		return ambientLight
+	}
+	return math.Min(ambientLight+intensity, 1)
+}
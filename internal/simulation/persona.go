@@ -0,0 +1,89 @@
+package simulation
+
+import "nightmare/internal/ai"
+
+// Reaction is what a bot does when something scares it
+type Reaction int
+
+const (
+	ReactionFlee        Reaction = iota // Turn away from the scare and run
+	ReactionFreeze                      // Stop and wait
+	ReactionInvestigate                 // Turn toward the scare and approach it
+)
+
+// String returns the name of the reaction
+func (r Reaction) String() string {
+	switch r {
+	case ReactionFlee:
+		return "flee"
+	case ReactionFreeze:
+		return "freeze"
+	default:
+		return "investigate"
+	}
+}
+
+// Persona scripts a bot that plays like one reactor type
+type Persona struct {
+	Name           string
+	Reactor        ai.ReactorType // Reactor type the observer should recognize
+	MoveEvery      int            // Ticks between steps while walking
+	PauseChance    float64        // Chance per tick to stop for a while
+	PauseTicks     int            // How long a pause lasts
+	ReverseChance  float64        // Chance per tick to turn back the way it came
+	TurnJitter     float64        // Chance per tick to turn randomly
+	InteractChance float64        // Chance per tick to interact with something
+	AttackChance   float64        // Chance per tick to swing at the dark
+	Reaction       Reaction       // What the bot does when scared
+	ReactionTicks  int            // How long the reaction lasts
+	Startle        float64        // Beats per minute a full-intensity scare adds to the heart rate
+}
+
+// Personas returns one persona for every reactor type.
+// The hesitant persona is expected to read as panic: the observer scores
+// hesitant as freezes minus interactions and panic as runs plus freezes,
+// so hesitant can never come out ahead
+func Personas() []Persona {
+	return []Persona{
+		{
+			Name: "cautious", Reactor: ai.ReactorCautious,
+			MoveEvery: 22, PauseChance: 0.006, PauseTicks: 60, ReverseChance: 0.001, TurnJitter: 0.021,
+			Reaction: ReactionFlee, ReactionTicks: 140, Startle: 35,
+		},
+		{
+			Name: "bold", Reactor: ai.ReactorBold,
+			MoveEvery: 3, PauseChance: 0.016, PauseTicks: 10, ReverseChance: 0.002, TurnJitter: 0.02, InteractChance: 0.0044, AttackChance: 0.013,
+			Reaction: ReactionInvestigate, ReactionTicks: 200, Startle: 15,
+		},
+		{
+			Name: "panic", Reactor: ai.ReactorPanic,
+			MoveEvery: 17, PauseChance: 0.0034, PauseTicks: 65, ReverseChance: 0.0047, TurnJitter: 0.166,
+			Reaction: ReactionFlee, ReactionTicks: 210, Startle: 55,
+		},
+		{
+			Name: "methodical", Reactor: ai.ReactorMethodical,
+			MoveEvery: 16, PauseChance: 0.006, PauseTicks: 60, TurnJitter: 0, InteractChance: 0.02,
+			Reaction: ReactionFreeze, ReactionTicks: 60, Startle: 20,
+		},
+		{
+			Name: "reckless", Reactor: ai.ReactorReckless,
+			MoveEvery: 6, PauseChance: 0, TurnJitter: 0.002, InteractChance: 0.002, AttackChance: 0.022,
+			Reaction: ReactionInvestigate, ReactionTicks: 280, Startle: 10,
+		},
+		{
+			Name: "hesitant", Reactor: ai.ReactorHesitant,
+			MoveEvery: 20, PauseChance: 0.015, PauseTicks: 120, ReverseChance: 0.01, TurnJitter: 0.05, InteractChance: 0.003,
+			Reaction: ReactionFreeze, ReactionTicks: 240, Startle: 40,
+		},
+	}
+}
+
+// FindPersona returns the persona with the given name
+func FindPersona(name string) (Persona, bool) {
+	for _, persona := range Personas() {
+		if persona.Name == name {
+			return persona, true
+		}
+	}
+	return Persona{}, false
+}
@@ -0,0 +1,400 @@
+// Package simulation runs the AI director against scripted player bots without a window,
+// so that pacing and scare numbers can be tuned without playtesting every change.
+package simulation
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	"nightmare/internal/ai"
+	"nightmare/internal/biometric"
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+	"nightmare/internal/event"
+	"nightmare/internal/world"
+)
+
+const (
+	tickRate       = 60 // Simulated ticks per second, as in the game
+	directorPeriod = 30 // Ticks between director updates, as in the game
+
+	restingHeartRate = 70  // Heart rate of a calm persona
+	maxHeartRate     = 160 // Top of the heart rate sparkline
+)
+
+// Config controls a simulation run
+type Config struct {
+	Ticks      int              // How long each persona plays
+	WorldSize  int              // Width and height of the generated world
+	Seed       int64            // Seed of the world, the director and the bots' decisions
+	Difficulty ai.Difficulty    // Pacing curve the director uses
+	Fairness   ai.FairnessRules // Guarantees the director must keep
+	Unfair     bool             // The director ignores the fairness rules, which are still checked
+	HeartRate  bool             // Feed the director and observer a simulated heart rate
+	Director   string           // Name of the director's personality, or "random" to roll one per run
+}
+
+// DefaultConfig returns a run of about five and a half minutes of play per persona
+func DefaultConfig() Config {
+	return Config{
+		Ticks:      20000,
+		WorldSize:  256,
+		Seed:       1,
+		Difficulty: ai.DifficultyNormal,
+		Fairness:   ai.DefaultFairnessRules(),
+		Director:   ai.PersonalityBalanced,
+	}
+}
+
+// ScareRecord is one scare the director played during a run
+type ScareRecord struct {
+	Tick      int
+	Type      common.ScareEventType
+	Intensity float64
+}
+
+// Report is what happened while a persona played
+type Report struct {
+	Persona    string
+	Director   string // Personality the director played with
+	Expected   ai.ReactorType
+	Classified ai.ReactorType
+	Reactors   map[ai.ReactorType]float64
+	Fears      map[ai.FearType]float64 // Fear profile the observer measured
+	Ticks      int                     // Ticks actually played
+	GameOver   bool                    // The persona lost all health or sanity
+	Scares     []ScareRecord
+	Sanity     []float64             // Sanity sampled once per simulated second
+	Health     []float64             // Health sampled once per simulated second
+	HeartRate  []float64             // Heart rate sampled once per simulated second, if simulated
+	Signals    map[ai.SignalType]int // Micro-behaviors recognized in the persona's movement
+	Avoided    map[string]int        // Fairness violations the director avoided, by rule
+	Violations []Violation           // Fairness guarantees broken despite the rules
+}
+
+// Correct checks whether the observer recognized the persona
+func (r *Report) Correct() bool {
+	return r.Classified == r.Expected
+}
+
+// Fair checks whether every fairness guarantee held
+func (r *Report) Fair() bool {
+	return len(r.Violations) == 0
+}
+
+// ScaresPerMinute returns the average scare rate
+func (r *Report) ScaresPerMinute() float64 {
+	minutes := float64(r.Ticks) / tickRate / 60
+	if minutes == 0 {
+		return 0
+	}
+	return float64(len(r.Scares)) / minutes
+}
+
+// Intervals returns the shortest, average and longest time between scares in seconds
+func (r *Report) Intervals() (float64, float64, float64) {
+	if len(r.Scares) < 2 {
+		return 0, 0, 0
+	}
+
+	shortest, longest, total := math.Inf(1), 0.0, 0.0
+	for i := 1; i < len(r.Scares); i++ {
+		interval := float64(r.Scares[i].Tick-r.Scares[i-1].Tick) / tickRate
+		shortest = math.Min(shortest, interval)
+		longest = math.Max(longest, interval)
+		total += interval
+	}
+	return shortest, total / float64(len(r.Scares)-1), longest
+}
+
+// Run lets one persona play against the director
+func Run(persona Persona, config Config) (*Report, error) {
+	// The director runs on simulated time, so thousands of ticks take seconds
+	now := time.Now()
+	common.SetClock(func() time.Time { return now })
+	defer common.SetClock(nil)
+
+	// World generation, the director and the creatures roll from the seed, so a run can be replayed
+	common.SeedRand(config.Seed)
+
+	w, err := world.NewWorld(config.WorldSize, config.WorldSize)
+	if err != nil {
+		return nil, err
+	}
+
+	player := entity.NewPlayer()
+	w.SetPlayer(player)
+	w.SetLightSampler(newFlashlight(w, player))
+
+	events := event.NewEventManager()
+	analyzer := ai.NewAnalyzer(player)
+	analyzer.SetWorldSize(w.Width, w.Height)
+	director := ai.NewDirector(player, w)
+	director.SetAnalyzer(analyzer)
+	director.SetEventManager(events)
+	director.SetDifficulty(config.Difficulty)
+	if config.Unfair {
+		director.SetFairnessRules(ai.FairnessRules{})
+	} else {
+		director.SetFairnessRules(config.Fairness)
+	}
+
+	personality, err := choosePersonality(config)
+	if err != nil {
+		return nil, err
+	}
+	director.SetPersonality(personality)
+
+	observer := ai.NewObserverSystem(player, events, analyzer, director)
+	observer.Initialize()
+	director.SetObserver(observer)
+
+	behavior := ai.NewBehaviorDetector(player, w)
+	director.SetBehaviorDetector(behavior)
+	report := &Report{Persona: persona.Name, Director: personality.Name, Expected: persona.Reactor, Signals: make(map[ai.SignalType]int)}
+	behavior.OnSignal(func(signal ai.BehaviorSignal) {
+		report.Signals[signal.Type]++
+	})
+
+	bot := newBot(player, persona, rand.New(rand.NewSource(config.Seed)), config.WorldSize, config.WorldSize)
+
+	// The persona's heart jumps at every scare, as much as the persona scares
+	var heart *biometric.Simulated
+	var monitor *ai.HeartMonitor
+	if config.HeartRate {
+		heart = biometric.NewSimulated(restingHeartRate, config.Seed)
+		monitor = ai.NewHeartMonitor(heart)
+		director.SetHeartMonitor(monitor)
+		observer.SetHeartMonitor(monitor)
+	}
+	fairness := newFairnessChecker(config.Fairness, w, player)
+
+	tick := 0
+	events.AddListener(event.EventScareTriggered, func(data event.EventData) {
+		record := ScareRecord{Tick: tick}
+		if scareType, ok := data.Custom["scareType"].(common.ScareEventType); ok {
+			record.Type = scareType
+		}
+		fairness.checkScare(tick, record.Type)
+		if intensity, ok := data.Value.(float64); ok {
+			record.Intensity = intensity
+		}
+		if heart != nil {
+			heart.Startle(record.Intensity * persona.Startle)
+		}
+		report.Scares = append(report.Scares, record)
+
+		if position, ok := data.Position.(common.Vector2D); ok {
+			bot.Scared(position)
+		}
+	})
+
+	publisher := newPlayerPublisher(player, w, events)
+	for tick = 1; tick <= config.Ticks; tick++ {
+		now = now.Add(time.Second / tickRate)
+
+		bot.Update()
+		player.Update()
+		w.Update()
+
+		publisher.publish()
+		events.ProcessEvents()
+		if monitor != nil {
+			monitor.Update()
+		}
+		behavior.Update()
+		observer.Update()
+
+		if tick%directorPeriod == 0 {
+			director.AnalyzePlayerBehavior()
+			director.AdjustWorld()
+		}
+		director.UpdateSequences()
+		fairness.checkSpawns(tick)
+
+		if tick%tickRate == 0 {
+			report.Sanity = append(report.Sanity, player.Sanity)
+			report.Health = append(report.Health, player.Health)
+			if monitor != nil {
+				rate, _ := monitor.Rate()
+				report.HeartRate = append(report.HeartRate, rate)
+			}
+		}
+
+		if player.Health <= 0 || player.Sanity <= 0 {
+			report.GameOver = true
+			break
+		}
+	}
+	report.Ticks = min(tick, config.Ticks)
+
+	observer.AnalyzePlayerBehavior()
+	report.Reactors = observer.GetPlayerReactorProfile()
+	report.Fears = observer.GetPlayerFearProfile()
+	report.Classified = dominantReactor(report.Reactors)
+	report.Avoided = director.AvoidedViolations()
+	report.Violations = fairness.violations
+
+	return report, nil
+}
+
+// RunAll lets every persona play in turn
+func RunAll(config Config) ([]*Report, error) {
+	reports := []*Report{}
+	for _, persona := range Personas() {
+		report, err := Run(persona, config)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", persona.Name, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// choosePersonality returns the personality named in the config, rolling one from the seed if asked to
+func choosePersonality(config Config) (ai.Personality, error) {
+	if config.Director == "random" {
+		return ai.RandomPersonality(rand.New(rand.NewSource(config.Seed))), nil
+	}
+
+	personality, ok := ai.FindPersonality(config.Director)
+	if !ok {
+		return ai.Personality{}, fmt.Errorf("unknown director personality %q", config.Director)
+	}
+	return personality, nil
+}
+
+// dominantReactor returns the reactor type with the highest score
+func dominantReactor(profile map[ai.ReactorType]float64) ai.ReactorType {
+	best := ai.ReactorCautious
+	bestValue := math.Inf(-1)
+	for reactor := ai.ReactorCautious; reactor <= ai.ReactorHesitant; reactor++ {
+		if profile[reactor] > bestValue {
+			best, bestValue = reactor, profile[reactor]
+		}
+	}
+	return best
+}
+
+// playerPublisher reports the player's movement, damage and sanity to the observer, as the game does
+type playerPublisher struct {
+	player       *entity.Player
+	world        *world.World
+	events       *event.EventManager
+	lastPosition entity.Vector2D
+	lastHealth   float64
+	lastSanity   float64
+}
+
+// newPlayerPublisher creates a publisher that starts from the player's current state
+func newPlayerPublisher(player *entity.Player, w *world.World, events *event.EventManager) *playerPublisher {
+	return &playerPublisher{
+		player:       player,
+		world:        w,
+		events:       events,
+		lastPosition: player.Position,
+		lastHealth:   player.Health,
+		lastSanity:   player.Sanity,
+	}
+}
+
+// publish sends events for everything that changed since the previous tick
+func (p *playerPublisher) publish() {
+	if p.player.Position != p.lastPosition {
+		p.events.TriggerWithData(event.NewPlayerMovedEvent(p.player, p.lastPosition, p.player.Position))
+		p.lastPosition = p.player.Position
+	}
+
+	if p.player.Health < p.lastHealth {
+		p.events.TriggerWithData(event.NewPlayerDamagedEvent(p.player, p.world, p.lastHealth-p.player.Health))
+	}
+	p.lastHealth = p.player.Health
+
+	if p.player.Sanity != p.lastSanity {
+		p.events.TriggerWithData(event.NewPlayerSanityChangedEvent(p.player, p.lastSanity, p.player.Sanity))
+		p.lastSanity = p.player.Sanity
+	}
+}
+
+// Write prints the report in a form designers can compare between runs
+func (r *Report) Write(w io.Writer) {
+	shortest, average, longest := r.Intervals()
+
+	verdict := "correct"
+	if !r.Correct() {
+		verdict = "WRONG"
+	}
+
+	fmt.Fprintf(w, "== %s vs %s director ==\n", r.Persona, r.Director)
+	fmt.Fprintf(w, "played %.0fs", float64(r.Ticks)/tickRate)
+	if r.GameOver {
+		fmt.Fprint(w, " (game over)")
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "scares: %d, %.2f per minute, interval min %.0fs avg %.0fs max %.0fs\n",
+		len(r.Scares), r.ScaresPerMinute(), shortest, average, longest)
+
+	counts := make(map[common.ScareEventType]int)
+	for _, scare := range r.Scares {
+		counts[scare.Type]++
+	}
+	for eventType := common.EventAmbientSound; eventType <= common.EventWhisper; eventType++ {
+		if counts[eventType] > 0 {
+			fmt.Fprintf(w, "  %s: %d\n", eventType, counts[eventType])
+		}
+	}
+
+	fmt.Fprintf(w, "sanity: %s\n", sparkline(r.Sanity, entity.MaxSanity))
+	fmt.Fprintf(w, "health: %s\n", sparkline(r.Health, entity.MaxHealth))
+	if len(r.HeartRate) > 0 {
+		fmt.Fprintf(w, "heart:  %s\n", sparkline(r.HeartRate, maxHeartRate))
+	}
+	fmt.Fprint(w, "signals:")
+	for signalType := ai.SignalFreeze; signalType <= ai.SignalSprint; signalType++ {
+		fmt.Fprintf(w, " %s %d", signalType, r.Signals[signalType])
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "observer: %s, expected %s (%s)\n", r.Classified, r.Expected, verdict)
+	for reactor := ai.ReactorCautious; reactor <= ai.ReactorHesitant; reactor++ {
+		fmt.Fprintf(w, "  %s: %.2f\n", reactor, r.Reactors[reactor])
+	}
+
+	fmt.Fprint(w, "places:")
+	for _, fearType := range []ai.FearType{ai.FearClaustrophobia, ai.FearOpenSpaces, ai.FearDarkness} {
+		fmt.Fprintf(w, " %s %.2f", fearType, r.Fears[fearType])
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "fairness: %d violations, avoided", len(r.Violations))
+	for _, rule := range []string{ai.RuleGrace, ai.RuleCriticalHealth, ai.RuleSpawnDistance, ai.RuleSafeZone, ai.RuleEscapeRoute} {
+		fmt.Fprintf(w, " %s %d", rule, r.Avoided[rule])
+	}
+	fmt.Fprintln(w)
+	for _, violation := range r.Violations {
+		fmt.Fprintf(w, "  %s\n", violation)
+	}
+}
+
+// sparkline draws a curve of values from 0 to maxValue in at most 60 characters
+func sparkline(values []float64, maxValue float64) string {
+	const width = 60
+	levels := []rune(" ▁▂▃▄▅▆▇█")
+
+	if len(values) == 0 {
+		return "-"
+	}
+
+	points := min(len(values), width)
+	line := make([]rune, 0, points)
+	for i := 0; i < points; i++ {
+		value := values[i*len(values)/points]
+		level := int(value / maxValue * float64(len(levels)-1))
+		level = max(0, min(level, len(levels)-1))
+		line = append(line, levels[level])
+	}
+	return fmt.Sprintf("%s %.0f -> %.0f", string(line), values[0], values[len(values)-1])
+}
@@ -47,6 +47,8 @@ const (
 	SoundLaughter     SoundID = "laughter"
 	SoundChildrenSong SoundID = "children_song"
 	SoundDripping     SoundID = "dripping"
+	SoundSwing        SoundID = "swing"
+	SoundImpact       SoundID = "impact"
 )
 
 // Sound представляет звук
@@ -95,6 +97,8 @@ type SoundManager struct {
 	lastHeartbeat time.Time
 	heartbeatRate float64 // удары в минуту
 
+	quiet bool // Тихое окно: случайные и пугающие звуки не воспроизводятся
+
 	random *rand.Rand
 }
 
@@ -161,6 +165,8 @@ func (sm *SoundManager) LoadAllSounds() {
 	sm.LoadSound(SoundFootstep, "sound/footstep.wav", SoundPlayer, false)
 	sm.LoadSound(SoundBreath, "sound/breath.wav", SoundPlayer, false)
 	sm.LoadSound(SoundHeartbeat, "sound/heartbeat.wav", SoundPlayer, false)
+	sm.LoadSound(SoundSwing, "sound/swing.wav", SoundPlayer, false)
+	sm.LoadSound(SoundImpact, "sound/impact.wav", SoundPlayer, false)
 
 	// Звуки окружения
 	sm.LoadSound(SoundCreak, "sound/creak.wav", SoundEffect, false)
@@ -364,6 +370,11 @@ func (sm *SoundManager) SetHeartbeatRate(bpm float64) {
 	sm.heartbeatRate = bpm
 }
 
+// SetQuiet включает или выключает тихое окно
+func (sm *SoundManager) SetQuiet(quiet bool) {
+	sm.quiet = quiet
+}
+
 // SetMasterVolume устанавливает общую громкость
 func (sm *SoundManager) SetMasterVolume(volume float64) {
 	sm.masterVolume = volume
@@ -382,6 +393,10 @@ func (sm *SoundManager) isActive(instanceID int) bool {
 
 // GenerateRandomSound генерирует случайный звук окружения
 func (sm *SoundManager) GenerateRandomSound(position Vector3D) {
+	if sm.quiet {
+		return
+	}
+
 	// Список звуков окружения
 	environmentSounds := []SoundID{
 		SoundCreak, SoundRustling, SoundThunder,
@@ -399,6 +414,10 @@ func (sm *SoundManager) GenerateRandomSound(position Vector3D) {
 
 // GenerateScareSound генерирует пугающий звук
 func (sm *SoundManager) GenerateScareSound(intensity float64, position Vector3D) {
+	if sm.quiet {
+		return
+	}
+
 	// Список пугающих звуков
 	scareSounds := []SoundID{
 		SoundWhisper, SoundScream, SoundGrowl,
@@ -0,0 +1,217 @@
+package world
+
+import (
+	"math"
+	"math/rand"
+
+	"nightmare/internal/common"
+)
+
+// Chunk settings
+const (
+	ChunkSize = 32 // Side of a chunk in tiles
+
+	safeChunkSpacing     = 4    // Trails run through every fourth row and column of chunks and cross in safe clearings
+	trailHalfWidth       = 1    // Tiles on each side of a trail's middle line
+	clearingRadius       = 10.0 // Radius of the clearing in the middle of a safe chunk
+	depthCorruption      = 12   // Chunks from the spawn point at which the forest is fully corrupted
+	maxNaturalCorruption = 0.6  // Corruption the forest reaches on its own, below what turns tiles impassable
+)
+
+// ChunkCoord identifies a chunk; chunk (0, 0) has its corner at the world origin
+type ChunkCoord struct {
+	X, Y int
+}
+
+// chunkOf returns the chunk containing the tile
+func chunkOf(x, y int) ChunkCoord {
+	return ChunkCoord{X: floorDiv(x, ChunkSize), Y: floorDiv(y, ChunkSize)}
+}
+
+// chunkAt returns the chunk containing the position
+func chunkAt(position common.Vector2D) ChunkCoord {
+	return chunkOf(int(math.Floor(position.X)), int(math.Floor(position.Y)))
+}
+
+// Origin returns the tile in the chunk's corner
+func (c ChunkCoord) Origin() (x, y int) {
+	return c.X * ChunkSize, c.Y * ChunkSize
+}
+
+// Center returns the middle of the chunk
+func (c ChunkCoord) Center() common.Vector2D {
+	x, y := c.Origin()
+	return common.Vector2D{X: float64(x) + ChunkSize/2, Y: float64(y) + ChunkSize/2}
+}
+
+// Bounds returns the area the chunk covers
+func (c ChunkCoord) Bounds() common.Bounds {
+	x, y := c.Origin()
+	return common.Bounds{
+		Min: common.Vector2D{X: float64(x), Y: float64(y)},
+		Max: common.Vector2D{X: float64(x + ChunkSize), Y: float64(y + ChunkSize)},
+	}
+}
+
+// distance returns how many chunks apart two chunks are, counting diagonal steps as one
+func (c ChunkCoord) distance(other ChunkCoord) int {
+	return max(abs(c.X-other.X), abs(c.Y-other.Y))
+}
+
+// depth returns how far the chunk lies from the spawn chunk
+func (c ChunkCoord) depth() int {
+	return c.distance(ChunkCoord{})
+}
+
+// safe checks whether trails cross in the chunk, leaving a safe clearing
+func (c ChunkCoord) safe() bool {
+	return c.trailColumn() && c.trailRow()
+}
+
+// trailColumn checks whether a trail runs through the chunk from north to south
+func (c ChunkCoord) trailColumn() bool {
+	return floorMod(c.X, safeChunkSpacing) == 0
+}
+
+// trailRow checks whether a trail runs through the chunk from west to east
+func (c ChunkCoord) trailRow() bool {
+	return floorMod(c.Y, safeChunkSpacing) == 0
+}
+
+// zone returns the zone covering the chunk: a safe clearing where trails cross,
+// elsewhere the more dangerous the deeper the chunk lies in the forest
+func (c ChunkCoord) zone() Zone {
+	depth := c.depth()
+
+	zoneType := ZoneNightmare
+	switch {
+	case c.safe():
+		zoneType = ZoneSafe
+	case depth <= 1:
+		zoneType = ZoneTransition
+	case depth <= 4:
+		zoneType = ZoneExploration
+	case depth <= 8:
+		zoneType = ZoneDanger
+	}
+
+	return Zone{
+		Type:        zoneType,
+		Position:    c.Center(),
+		Radius:      ChunkSize / 2,
+		Density:     0.5,
+		Theme:       ThemeForest,
+		Corruption:  math.Min(1, float64(depth)/depthCorruption),
+		Connections: []int{},
+	}
+}
+
+// Chunk is a square piece of an endless world
+type Chunk struct {
+	Coord    ChunkCoord
+	Tiles    [][]Tile // Indexed [y][x] from the chunk's corner
+	Modified bool     // Changed since it was generated, so it must be stored when unloaded
+}
+
+// tile returns the tile at the world coordinates, which must lie in the chunk
+func (c *Chunk) tile(x, y int) *Tile {
+	originX, originY := c.Coord.Origin()
+	return &c.Tiles[y-originY][x-originX]
+}
+
+// generateChunk builds a chunk from the world seed alone, so that it comes out
+// the same every time it is loaded and matches its neighbours at the edges
+func (w *World) generateChunk(coord ChunkCoord) *Chunk {
+	const elevationScale = 0.05
+	const moistureScale = 0.07
+	const corruptionScale = 0.11
+
+	chunk := &Chunk{Coord: coord, Tiles: make([][]Tile, ChunkSize)}
+	random := rand.New(rand.NewSource(chunkSeed(w.stream.seed, coord)))
+	corruption := coord.zone().Corruption * maxNaturalCorruption
+	center := coord.Center()
+	originX, originY := coord.Origin()
+
+	for ty := 0; ty < ChunkSize; ty++ {
+		chunk.Tiles[ty] = make([]Tile, ChunkSize)
+		for tx := 0; tx < ChunkSize; tx++ {
+			x, y := originX+tx, originY+ty
+			position := common.Vector2D{X: float64(x), Y: float64(y)}
+
+			// The same noise as a fixed world, sampled at world coordinates
+			elevation := (w.noise.Eval2(float64(x)*elevationScale, float64(y)*elevationScale) + 1) / 2
+			moisture := (w.noise.Eval2(float64(x)*moistureScale+100, float64(y)*moistureScale+100) + 1) / 2
+
+			tile := &chunk.Tiles[ty][tx]
+			*tile = Tile{
+				Type:      w.determineTileType(elevation, moisture),
+				Position:  position,
+				Elevation: elevation,
+				Moisture:  moisture,
+				Objects:   []common.WorldObject{},
+			}
+
+			// Trails and clearings are always passable, so the player is never walled in
+			if coord.safe() && distance(position, center) < clearingRadius {
+				tile.Type = common.TileGrass
+				continue
+			}
+			if onTrail(coord, tx, ty) {
+				tile.Type = common.TilePath
+				continue
+			}
+
+			// The deeper into the forest, the more it rots
+			tile.Corruption = corruption * (w.noise.Eval2(float64(x)*corruptionScale+200, float64(y)*corruptionScale+200) + 1) / 2
+
+			object, ok := naturalObject(tile.Type, random.Float64)
+			if !ok {
+				continue
+			}
+			object.ID = w.nextID
+			object.Position = position
+			tile.Objects = append(tile.Objects, object)
+			w.nextID++
+		}
+	}
+
+	return chunk
+}
+
+// onTrail checks whether a tile of the chunk lies on a trail
+func onTrail(coord ChunkCoord, tx, ty int) bool {
+	return (coord.trailColumn() && abs(tx-ChunkSize/2) <= trailHalfWidth) ||
+		(coord.trailRow() && abs(ty-ChunkSize/2) <= trailHalfWidth)
+}
+
+// chunkSeed mixes the world seed with the chunk coordinates
+func chunkSeed(seed int64, coord ChunkCoord) int64 {
+	h := uint64(seed)
+	h ^= uint64(int64(coord.X)) * 0x9E3779B97F4A7C15
+	h = (h ^ h>>31) * 0xBF58476D1CE4E5B9
+	h ^= uint64(int64(coord.Y)) * 0x94D049BB133111EB
+	h = (h ^ h>>29) * 0xBF58476D1CE4E5B9
+	return int64(h ^ h>>32)
+}
+
+// floorDiv divides rounding towards negative infinity
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// floorMod returns the remainder of floorDiv, which has the sign of b
+func floorMod(a, b int) int {
+	return a - floorDiv(a, b)*b
+}
+
+// abs returns the absolute value
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,168 @@
+package world
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"nightmare/internal/common"
+)
+
+// forestFile holds the seed of an endless world in its store directory
+const forestFile = "forest.json"
+
+// ChunkStore keeps the chunks of an endless world that differ from what the seed generates:
+// corrupted ground, dropped items. With a directory they outlive the game,
+// without one they last while the store exists.
+type ChunkStore struct {
+	dir     string
+	records map[ChunkCoord]*chunkRecord
+}
+
+// chunkRecord is the saved state of a modified chunk
+type chunkRecord struct {
+	X     int         `json:"x"`
+	Y     int         `json:"y"`
+	Tiles []savedTile `json:"tiles"` // Row by row from the chunk's corner
+}
+
+// savedTile is what a tile keeps of its changes; the rest is generated again
+type savedTile struct {
+	Type       TileType             `json:"type"`
+	Corruption float64              `json:"corruption,omitempty"`
+	Objects    []common.WorldObject `json:"objects,omitempty"`
+}
+
+// forestRecord is the saved description of an endless world
+type forestRecord struct {
+	Seed int64 `json:"seed"`
+}
+
+// NewChunkStore creates a store saving chunks in the directory; an empty path keeps them in memory only
+func NewChunkStore(dir string) *ChunkStore {
+	return &ChunkStore{
+		dir:     dir,
+		records: make(map[ChunkCoord]*chunkRecord),
+	}
+}
+
+// DefaultForestDir returns where the local endless world is stored
+func DefaultForestDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "nightmare", "forest")
+}
+
+// LoadSeed reads the seed of the stored world. A store that holds no world reports os.ErrNotExist.
+func (s *ChunkStore) LoadSeed() (int64, error) {
+	if s.dir == "" {
+		return 0, os.ErrNotExist
+	}
+
+	var forest forestRecord
+	if err := readJSON(filepath.Join(s.dir, forestFile), &forest); err != nil {
+		return 0, err
+	}
+	return forest.Seed, nil
+}
+
+// SaveSeed records the seed of the stored world
+func (s *ChunkStore) SaveSeed(seed int64) error {
+	if s.dir == "" {
+		return nil
+	}
+	return writeJSON(filepath.Join(s.dir, forestFile), forestRecord{Seed: seed})
+}
+
+// save stores the chunk. The record stays in memory even if writing it fails,
+// so the change is not lost while the game runs.
+func (s *ChunkStore) save(chunk *Chunk) error {
+	record := &chunkRecord{X: chunk.Coord.X, Y: chunk.Coord.Y, Tiles: make([]savedTile, 0, ChunkSize*ChunkSize)}
+	for _, row := range chunk.Tiles {
+		for _, tile := range row {
+			record.Tiles = append(record.Tiles, savedTile{
+				Type:       tile.Type,
+				Corruption: tile.Corruption,
+				Objects:    append([]common.WorldObject(nil), tile.Objects...),
+			})
+		}
+	}
+	s.records[chunk.Coord] = record
+
+	if s.dir == "" {
+		return nil
+	}
+	return writeJSON(s.chunkPath(chunk.Coord), record)
+}
+
+// load returns the stored record of the chunk, or nil if the chunk was never modified
+func (s *ChunkStore) load(coord ChunkCoord) (*chunkRecord, error) {
+	if record, ok := s.records[coord]; ok {
+		return record, nil
+	}
+	if s.dir == "" {
+		return nil, nil
+	}
+
+	record := &chunkRecord{}
+	err := readJSON(s.chunkPath(coord), record)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(record.Tiles) != ChunkSize*ChunkSize {
+		return nil, fmt.Errorf("chunk %d,%d: %d tiles saved, %d expected", coord.X, coord.Y, len(record.Tiles), ChunkSize*ChunkSize)
+	}
+
+	s.records[coord] = record
+	return record, nil
+}
+
+// chunkPath returns the file of the chunk
+func (s *ChunkStore) chunkPath(coord ChunkCoord) string {
+	return filepath.Join(s.dir, fmt.Sprintf("chunk_%d_%d.json", coord.X, coord.Y))
+}
+
+// apply overwrites the generated chunk with the saved changes
+func (r *chunkRecord) apply(chunk *Chunk) {
+	for i, saved := range r.Tiles {
+		tile := &chunk.Tiles[i/ChunkSize][i%ChunkSize]
+		tile.Type = saved.Type
+		tile.Corruption = saved.Corruption
+		tile.Objects = append([]common.WorldObject{}, saved.Objects...)
+	}
+	chunk.Modified = true
+}
+
+// readJSON decodes a file
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSON encodes a file, replacing it only once it is completely written
+func writeJSON(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
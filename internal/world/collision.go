@@ -11,6 +11,7 @@ import (
 type CollisionSystem struct {
 	world        *World
 	cellSize     float64
+	origin       common.Vector2D // World position of the corner of the collision map
 	collisionMap [][]bool
 }
 
@@ -24,24 +25,39 @@ type CollisionResult struct {
 
 // NewCollisionSystem creates a new collision system
 func NewCollisionSystem(world *World, cellSize float64) *CollisionSystem {
+	cs := &CollisionSystem{
+		world:    world,
+		cellSize: cellSize,
+	}
+
 	// Create collision map
-	width := int(math.Ceil(float64(world.Width) / cellSize))
-	height := int(math.Ceil(float64(world.Height) / cellSize))
+	cs.resize(world.Bounds())
+
+	return cs
+}
+
+// resize makes the collision map cover the bounds, reusing the cells if the size is unchanged
+func (cs *CollisionSystem) resize(bounds common.Bounds) {
+	cs.origin = bounds.Min
 
-	collisionMap := make([][]bool, height)
-	for y := range collisionMap {
-		collisionMap[y] = make([]bool, width)
+	width := int(math.Ceil((bounds.Max.X - bounds.Min.X) / cs.cellSize))
+	height := int(math.Ceil((bounds.Max.Y - bounds.Min.Y) / cs.cellSize))
+	if len(cs.collisionMap) == height && (height == 0 || len(cs.collisionMap[0]) == width) {
+		return
 	}
 
-	return &CollisionSystem{
-		world:        world,
-		cellSize:     cellSize,
-		collisionMap: collisionMap,
+	cs.collisionMap = make([][]bool, height)
+	for y := range cs.collisionMap {
+		cs.collisionMap[y] = make([]bool, width)
 	}
 }
 
 // UpdateCollisionMap updates the collision map
 func (cs *CollisionSystem) UpdateCollisionMap() {
+	// The loaded part of an endless world changes as the player walks
+	bounds := cs.world.Bounds()
+	cs.resize(bounds)
+
 	// Reset collision map
 	for y := range cs.collisionMap {
 		for x := range cs.collisionMap[y] {
@@ -50,23 +66,14 @@ func (cs *CollisionSystem) UpdateCollisionMap() {
 	}
 
 	// Update collisions based on tiles
-	for y := 0; y < cs.world.Height; y++ {
-		for x := 0; x < cs.world.Width; x++ {
+	for y := int(bounds.Min.Y); y < int(bounds.Max.Y); y++ {
+		for x := int(bounds.Min.X); x < int(bounds.Max.X); x++ {
+			// A tile that is not loaded cannot be entered
 			tile := cs.world.GetTileAt(x, y)
-			if tile == nil {
-				continue
-			}
 
 			// Check if the tile is impassable
-			if cs.isTileSolid(tile) {
-				// Calculate indexes in the collision map
-				cellX := int(float64(x) / cs.cellSize)
-				cellY := int(float64(y) / cs.cellSize)
-
-				// Check that indexes are within the map
-				if cellX >= 0 && cellX < len(cs.collisionMap[0]) && cellY >= 0 && cellY < len(cs.collisionMap) {
-					cs.collisionMap[cellY][cellX] = true
-				}
+			if tile == nil || cs.isTileSolid(tile) {
+				cs.markSolid(common.Vector2D{X: float64(x), Y: float64(y)})
 			}
 		}
 	}
@@ -74,26 +81,31 @@ func (cs *CollisionSystem) UpdateCollisionMap() {
 	// Add collisions from objects
 	for _, obj := range cs.world.Objects {
 		if obj.Solid {
-			// Calculate indexes in the collision map
-			cellX := int(obj.Position.X / cs.cellSize)
-			cellY := int(obj.Position.Y / cs.cellSize)
-
-			// Check that indexes are within the map
-			if cellX >= 0 && cellX < len(cs.collisionMap[0]) && cellY >= 0 && cellY < len(cs.collisionMap) {
-				cs.collisionMap[cellY][cellX] = true
-			}
+			cs.markSolid(obj.Position)
 		}
 	}
 }
 
+// markSolid marks the cell containing the position as impassable
+func (cs *CollisionSystem) markSolid(position common.Vector2D) {
+	if cellX, cellY, ok := cs.cellAt(position); ok {
+		cs.collisionMap[cellY][cellX] = true
+	}
+}
+
+// cellAt returns the indexes of the collision map cell containing the position.
+// ok is false if the position is outside the map.
+func (cs *CollisionSystem) cellAt(position common.Vector2D) (cellX, cellY int, ok bool) {
+	cellX = int(math.Floor((position.X - cs.origin.X) / cs.cellSize))
+	cellY = int(math.Floor((position.Y - cs.origin.Y) / cs.cellSize))
+	ok = cellY >= 0 && cellY < len(cs.collisionMap) && cellX >= 0 && cellX < len(cs.collisionMap[cellY])
+	return cellX, cellY, ok
+}
+
 // CheckCollision checks for a collision at the specified position
 func (cs *CollisionSystem) CheckCollision(position common.Vector2D) bool {
-	// Calculate indexes in the collision map
-	cellX := int(position.X / cs.cellSize)
-	cellY := int(position.Y / cs.cellSize)
-
-	// Check that indexes are within the map
-	if cellX < 0 || cellX >= len(cs.collisionMap[0]) || cellY < 0 || cellY >= len(cs.collisionMap) {
+	cellX, cellY, ok := cs.cellAt(position)
+	if !ok {
 		return true // Outside the world is considered a collision
 	}
 
@@ -198,6 +210,7 @@ func (cs *CollisionSystem) CastRay(start common.Vector2D, direction common.Vecto
 
 	// Raycast step
 	stepSize := cs.cellSize * 0.5
+	bounds := cs.world.Bounds()
 
 	// Check points along the ray path
 	for dist := 0.0; dist <= maxDistance; dist += stepSize {
@@ -207,8 +220,7 @@ func (cs *CollisionSystem) CastRay(start common.Vector2D, direction common.Vecto
 		}
 
 		// Check if the point is within the world
-		if checkPoint.X < 0 || checkPoint.X >= float64(cs.world.Width) ||
-			checkPoint.Y < 0 || checkPoint.Y >= float64(cs.world.Height) {
+		if !bounds.Contains(checkPoint) {
 			// Reached the world boundary
 			return CollisionResult{
 				HasCollision: true,
@@ -226,8 +238,7 @@ func (cs *CollisionSystem) CastRay(start common.Vector2D, direction common.Vecto
 			var hitObject interface{}
 
 			// Check tile
-			tileX, tileY := int(checkPoint.X), int(checkPoint.Y)
-			tile := cs.world.GetTileAt(tileX, tileY)
+			tile := cs.world.tileAt(checkPoint)
 			if tile != nil && cs.isTileSolid(tile) {
 				hitObject = tile
 			}
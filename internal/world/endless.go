@@ -0,0 +1,298 @@
+package world
+
+import (
+	"sort"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+
+	"github.com/ojrac/opensimplex-go"
+)
+
+// Streaming settings
+const (
+	chunkLoadRadius = 2 // Chunks this close to the player's chunk are loaded
+	chunkKeepRadius = 3 // Loaded chunks are unloaded only farther than this, so walking along a border does not thrash
+	mapAreaRadius   = 8 // Chunks on each side of the spawn chunk that the map of an endless world covers
+)
+
+// chunkStream holds the loaded chunks of an endless world and what was left in the others
+type chunkStream struct {
+	seed     int64
+	store    *ChunkStore
+	chunks   map[ChunkCoord]*Chunk
+	dormant  map[ChunkCoord][]*Entity // Creatures left behind in unloaded chunks
+	bounds   common.Bounds            // Area covered by the loaded chunks
+	center   ChunkCoord               // Chunk the world was last streamed around
+	streamed bool                     // The chunks around center are loaded
+	err      error                    // First error met while storing or loading chunks
+}
+
+// NewEndlessWorld creates a world without edges: chunks are generated from the seed
+// as the player approaches, and the ones changed are kept in the store when left behind.
+// A nil store keeps them in memory.
+func NewEndlessWorld(seed int64, store *ChunkStore) (*World, error) {
+	if store == nil {
+		store = NewChunkStore("")
+	}
+
+	world := &World{
+		Entities: []*Entity{},
+		Objects:  []common.WorldObject{},
+		nextID:   1,
+		noise:    opensimplex.New(seed),
+
+		creatureGen: entity.NewCreatureGenerator(),
+
+		stream: &chunkStream{
+			seed:    seed,
+			store:   store,
+			chunks:  make(map[ChunkCoord]*Chunk),
+			dormant: make(map[ChunkCoord][]*Entity),
+		},
+	}
+
+	// The chunks around the spawn point are there before the player arrives
+	world.streamAround(world.SpawnPoint())
+	if err := world.stream.err; err != nil {
+		return nil, err
+	}
+
+	return world, nil
+}
+
+// Endless checks whether the world is streamed in chunks
+func (w *World) Endless() bool {
+	return w.stream != nil
+}
+
+// LoadedChunks returns the number of chunks in memory; 0 for a world of fixed size
+func (w *World) LoadedChunks() int {
+	if w.stream == nil {
+		return 0
+	}
+	return len(w.stream.chunks)
+}
+
+// SaveChunks stores every modified chunk still loaded. It reports the first error
+// met since the world was created, including errors while streaming.
+func (w *World) SaveChunks() error {
+	if w.stream == nil {
+		return nil
+	}
+
+	for _, chunk := range w.stream.chunks {
+		if chunk.Modified {
+			w.stream.fail(w.stream.store.save(chunk))
+		}
+	}
+	return w.stream.err
+}
+
+// streamChunks keeps the chunks around the player loaded
+func (w *World) streamChunks() {
+	if w.stream == nil || w.player == nil {
+		return
+	}
+	w.streamAround(w.player.Position.ToCommonVector())
+}
+
+// streamAround loads the chunks near the position and unloads the distant ones
+func (w *World) streamAround(position common.Vector2D) {
+	s := w.stream
+	center := chunkAt(position)
+	if s.streamed && center == s.center {
+		return
+	}
+	s.center = center
+	s.streamed = true
+
+	changed := false
+	for coord, chunk := range s.chunks {
+		if coord.distance(center) > chunkKeepRadius {
+			w.unloadChunk(chunk)
+			changed = true
+		}
+	}
+
+	for dy := -chunkLoadRadius; dy <= chunkLoadRadius; dy++ {
+		for dx := -chunkLoadRadius; dx <= chunkLoadRadius; dx++ {
+			coord := ChunkCoord{X: center.X + dx, Y: center.Y + dy}
+			if _, ok := s.chunks[coord]; !ok {
+				w.loadChunk(coord)
+				changed = true
+			}
+		}
+	}
+
+	if changed {
+		w.chunksChanged()
+	}
+}
+
+// loadChunk generates the chunk, applies its stored changes and brings back the creatures left in it
+func (w *World) loadChunk(coord ChunkCoord) {
+	s := w.stream
+	chunk := w.generateChunk(coord)
+
+	// A chunk that cannot be read is generated afresh rather than leaving a hole in the world
+	record, err := s.store.load(coord)
+	s.fail(err)
+	if record != nil {
+		record.apply(chunk)
+
+		// Stored objects take new IDs, which are only unique within one world
+		for _, row := range chunk.Tiles {
+			for i := range row {
+				tile := &row[i]
+				for j := range tile.Objects {
+					tile.Objects[j].ID = w.nextID
+					w.nextID++
+				}
+			}
+		}
+	}
+	s.chunks[coord] = chunk
+
+	// They sleep until the player comes near
+	if sleepers := s.dormant[coord]; len(sleepers) > 0 {
+		population := w.Population()
+		population.hibernating = append(population.hibernating, sleepers...)
+		delete(s.dormant, coord)
+	}
+}
+
+// unloadChunk stores the chunk if it was modified and drops it from memory.
+// The creatures in it are left there by settleCreatures.
+func (w *World) unloadChunk(chunk *Chunk) {
+	if chunk.Modified {
+		w.stream.fail(w.stream.store.save(chunk))
+	}
+	delete(w.stream.chunks, chunk.Coord)
+}
+
+// chunksChanged updates everything that depends on which chunks are loaded
+func (w *World) chunksChanged() {
+	s := w.stream
+
+	// The loaded chunks always form a square around the player, but bounds cover any shape
+	first := true
+	for coord := range s.chunks {
+		bounds := coord.Bounds()
+		if first {
+			s.bounds = bounds
+			first = false
+			continue
+		}
+		s.bounds.Min.X = min(s.bounds.Min.X, bounds.Min.X)
+		s.bounds.Min.Y = min(s.bounds.Min.Y, bounds.Min.Y)
+		s.bounds.Max.X = max(s.bounds.Max.X, bounds.Max.X)
+		s.bounds.Max.Y = max(s.bounds.Max.Y, bounds.Max.Y)
+	}
+
+	// Objects are listed for collisions and raycasts
+	w.Objects = w.Objects[:0]
+	for _, chunk := range s.chunks {
+		for _, row := range chunk.Tiles {
+			for _, tile := range row {
+				w.Objects = append(w.Objects, tile.Objects...)
+			}
+		}
+	}
+
+	w.settleCreatures()
+	w.Population().SetZones(w.chunkZones())
+
+	if w.collision != nil {
+		w.collision.UpdateCollisionMap()
+	}
+	w.spatialStale = true
+}
+
+// settleCreatures leaves every creature standing outside the loaded chunks in the chunk it stands in.
+// Creatures walk from chunk to chunk freely while both are loaded; this is where they stay behind.
+func (w *World) settleCreatures() {
+	s := w.stream
+	leave := func(entities []*Entity) []*Entity {
+		kept := entities[:0]
+		for _, e := range entities {
+			coord := chunkAt(e.Position)
+			if _, ok := s.chunks[coord]; ok {
+				kept = append(kept, e)
+				continue
+			}
+			if e.Creature == nil || !e.Creature.IsDead() {
+				s.dormant[coord] = append(s.dormant[coord], e)
+			}
+		}
+		return kept
+	}
+
+	w.Entities = leave(w.Entities)
+	population := w.Population()
+	population.hibernating = leave(population.hibernating)
+}
+
+// chunkZones returns a zone for every loaded chunk, each connected to the loaded chunks beside it
+func (w *World) chunkZones() []Zone {
+	coords := make([]ChunkCoord, 0, len(w.stream.chunks))
+	for coord := range w.stream.chunks {
+		coords = append(coords, coord)
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].Y != coords[j].Y {
+			return coords[i].Y < coords[j].Y
+		}
+		return coords[i].X < coords[j].X
+	})
+
+	index := make(map[ChunkCoord]int, len(coords))
+	for i, coord := range coords {
+		index[coord] = i
+	}
+
+	zones := make([]Zone, len(coords))
+	for i, coord := range coords {
+		zones[i] = coord.zone()
+		for _, step := range []ChunkCoord{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}} {
+			if j, ok := index[ChunkCoord{X: coord.X + step.X, Y: coord.Y + step.Y}]; ok {
+				zones[i].Connections = append(zones[i].Connections, j)
+			}
+		}
+	}
+	return zones
+}
+
+// markModified remembers that the chunk holding the tile must be stored; fixed worlds ignore it
+func (w *World) markModified(tile *Tile) {
+	if w.stream == nil {
+		return
+	}
+	if chunk, ok := w.stream.chunks[chunkAt(tile.Position)]; ok {
+		chunk.Modified = true
+	}
+}
+
+// tileAt returns the tile at the world coordinates, or nil if its chunk is not loaded
+func (s *chunkStream) tileAt(x, y int) *Tile {
+	chunk, ok := s.chunks[chunkOf(x, y)]
+	if !ok {
+		return nil
+	}
+	return chunk.tile(x, y)
+}
+
+// fail records the error unless an earlier one is already recorded
+func (s *chunkStream) fail(err error) {
+	if err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// mapArea returns the part of an endless world worth mapping, centred on the spawn chunk
+func mapArea() common.Bounds {
+	return common.Bounds{
+		Min: ChunkCoord{X: -mapAreaRadius, Y: -mapAreaRadius}.Bounds().Min,
+		Max: ChunkCoord{X: mapAreaRadius, Y: mapAreaRadius}.Bounds().Max,
+	}
+}
@@ -2,7 +2,6 @@ package world
 
 import (
 	"math"
-	"math/rand"
 
 	"nightmare/internal/ai"
 	"nightmare/internal/common"
@@ -35,6 +34,23 @@ const (
 	ZoneTransition                  // Переходная зона
 )
 
+// String возвращает название типа зоны
+func (z ZoneType) String() string {
+	switch z {
+	case ZoneSafe:
+		return "safe"
+	case ZoneExploration:
+		return "exploration"
+	case ZoneDanger:
+		return "danger"
+	case ZoneNightmare:
+		return "nightmare"
+	case ZoneTransition:
+		return "transition"
+	}
+	return "unknown"
+}
+
 // Zone представляет зону в мире
 type Zone struct {
 	Type        ZoneType
@@ -69,8 +85,8 @@ type Generator struct {
 func NewGenerator(world *World, width, height int) *Generator {
 	return &Generator{
 		world:  world,
-		random: util.NewRandomGenerator(rand.Int63()),
-		noise:  util.NewNoiseGenerator(rand.Int63()),
+		random: util.NewRandomGenerator(common.Rand().Int63()),
+		noise:  util.NewNoiseGenerator(common.Rand().Int63()),
 
 		width:  width,
 		height: height,
@@ -119,6 +135,7 @@ func (g *Generator) GenerateWorld() error {
 
 	// Генерируем зоны
 	g.generateZones()
+	g.world.Population().SetZones(g.zones)
 
 	// Генерируем объекты
 	g.generateObjects()
@@ -310,6 +327,14 @@ func (g *Generator) determineTileType(elevation, moisture, density, corruption f
 
 // generateZones генерирует зоны мира
 func (g *Generator) generateZones() {
+	g.layoutZones()
+
+	// Применяем зоны к миру
+	g.applyZonesToWorld()
+}
+
+// layoutZones размечает зоны и граф связей между ними, не меняя тайлы
+func (g *Generator) layoutZones() {
 	// Очищаем существующие зоны
 	g.zones = []Zone{}
 
@@ -402,9 +427,6 @@ func (g *Generator) generateZones() {
 
 		g.zones = append(g.zones, zone)
 	}
-
-	// Применяем зоны к миру
-	g.applyZonesToWorld()
 }
 
 // selectZoneTheme выбирает тему для зоны
@@ -807,8 +829,11 @@ func (g *Generator) generateCreatures() {
 			// Выбираем тип существа в зависимости от зоны и темы
 			creatureType := g.selectCreatureType(zone.Type, zone.Theme, tile.Corruption)
 
-			// Создаем существо
-			creature := g.world.SpawnCreature(creatureType, common.ConvertPosition(x, y))
+			// Создаем существо через менеджер популяции, он следит за бюджетами
+			creature := g.world.Population().Spawn(creatureType, common.ConvertPosition(x, y))
+			if creature == nil {
+				continue
+			}
 
 			// Настраиваем существо в зависимости от типа зоны
 			g.configureCreatureForZone(creature, zone.Type)
@@ -0,0 +1,249 @@
+package world
+
+import (
+	"math"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+// HallucinationKind is what the player imagines
+type HallucinationKind int
+
+const (
+	HallucinationCreature HallucinationKind = iota // A creature watching the player
+	HallucinationObject                            // An object that should not be there
+	HallucinationLight                             // A light in the distance
+	HallucinationPath                              // A trail of footprints leading away
+)
+
+// Hallucination parameters
+const (
+	hallucinationSanityStart = 0.8  // Hallucinations start below this fraction of sanity
+	hallucinationMaxChance   = 0.6  // Chance of a new hallucination per check at zero sanity
+	hallucinationCheckRate   = 60   // Frames between spawn checks
+	hallucinationMaxActive   = 6    // Maximum number of hallucinations at once
+	hallucinationRealChance  = 0.1  // Chance that an imagined creature is really there
+	hallucinationVanishDist  = 3.0  // Hallucinations vanish when the player gets this close
+	hallucinationRevealSlack = 2.0  // A real creature steps out this much before the player is closer than spawns may be
+	hallucinationLitLevel    = 0.5  // Hallucinations vanish in light brighter than this
+	hallucinationLitGrace    = 45   // Frames a hallucination survives in light, long enough to be glimpsed
+	hallucinationMinLifetime = 180  // Minimum lifetime of a random hallucination in frames
+	hallucinationMaxLifetime = 600  // Maximum lifetime of a random hallucination in frames
+	hallucinationPathLength  = 12   // Number of footprints in a path hallucination
+	hallucinationPathStep    = 1.2  // Distance between footprints
+	hallucinationMinDistance = 8.0  // Random hallucinations appear at least this far from the player
+	hallucinationMaxDistance = 20.0 // Random hallucinations appear at most this far from the player
+)
+
+var (
+	hallucinationCreatures = []string{"shadow", "spider", "phantom", "wendigo", "faceless"}
+	hallucinationObjects   = []string{"doll", "totem", "bones", "cloth"}
+)
+
+// Hallucination exists only in the player's perception. It has no collision and
+// creatures do not know about it, but the renderer and the sound system can read it.
+type Hallucination struct {
+	ID        int
+	Kind      HallucinationKind
+	Type      string // Creature type or object name
+	Position  common.Vector2D
+	Direction float64
+	Path      []common.Vector2D // Footprints of a path hallucination
+	Lifetime  int               // Frames left
+	Age       int               // Frames since it appeared
+	Real      bool              // A real creature that only looks like a hallucination
+
+	litTime int // Frames spent in bright light
+}
+
+// PerceptionLayer holds everything that exists only in the player's mind
+type PerceptionLayer struct {
+	world          *World
+	hallucinations []*Hallucination
+	nextID         int
+	frame          int
+}
+
+// NewPerceptionLayer creates an empty perception layer
+func NewPerceptionLayer(world *World) *PerceptionLayer {
+	return &PerceptionLayer{
+		world:          world,
+		hallucinations: []*Hallucination{},
+		nextID:         1,
+	}
+}
+
+// Hallucinations returns the hallucinations the player currently perceives
+func (p *PerceptionLayer) Hallucinations() []*Hallucination {
+	return p.hallucinations
+}
+
+// Spawn adds a hallucination for the given number of frames
+func (p *PerceptionLayer) Spawn(kind HallucinationKind, hallucinationType string, position common.Vector2D, frames int) *Hallucination {
+	h := &Hallucination{
+		ID:       p.nextID,
+		Kind:     kind,
+		Type:     hallucinationType,
+		Position: position,
+		Lifetime: frames,
+	}
+	p.nextID++
+
+	if player := p.world.player; player != nil {
+		playerPos := player.Position.ToCommonVector()
+
+		// Imagined creatures face the player
+		h.Direction = math.Atan2(playerPos.Y-position.Y, playerPos.X-position.X)
+
+		// Footprints lead away from the player
+		if kind == HallucinationPath {
+			h.Direction += math.Pi
+			h.Path = p.footprints(position, h.Direction)
+		}
+	}
+
+	p.hallucinations = append(p.hallucinations, h)
+	return h
+}
+
+// Update ages hallucinations, removes the ones that vanished and imagines new ones as sanity drops
+func (p *PerceptionLayer) Update() {
+	p.frame++
+
+	remaining := p.hallucinations[:0]
+	for _, h := range p.hallucinations {
+		h.Age++
+		h.Lifetime--
+
+		if h.Lifetime <= 0 || p.shouldVanish(h) {
+			p.vanish(h)
+			continue
+		}
+		remaining = append(remaining, h)
+	}
+	p.hallucinations = remaining
+
+	if p.frame%hallucinationCheckRate == 0 {
+		p.imagine()
+	}
+}
+
+// shouldVanish checks whether the player has come close to the hallucination or lit it up
+func (p *PerceptionLayer) shouldVanish(h *Hallucination) bool {
+	player := p.world.player
+	if player == nil {
+		return false
+	}
+
+	if distance(h.Position, player.Position.ToCommonVector()) < p.vanishDistance(h) {
+		return true
+	}
+
+	// Imagined lights and footprints do not fear the light
+	if h.Kind == HallucinationLight || h.Kind == HallucinationPath || p.world.light == nil {
+		return false
+	}
+
+	if p.world.light.LightLevelAt(h.Position) > hallucinationLitLevel {
+		h.litTime++
+	} else {
+		h.litTime = 0
+	}
+	return h.litTime > hallucinationLitGrace
+}
+
+// vanishDistance returns how close the player gets before the hallucination vanishes.
+// A real creature must step out while the spawn rules still allow it there.
+func (p *PerceptionLayer) vanishDistance(h *Hallucination) float64 {
+	if !h.Real {
+		return hallucinationVanishDist
+	}
+	return math.Max(hallucinationVanishDist, p.world.Population().MinPlayerDistance+hallucinationRevealSlack)
+}
+
+// vanish removes a hallucination; a real creature steps out instead,
+// if the population manager lets it spawn nearby
+func (p *PerceptionLayer) vanish(h *Hallucination) {
+	if !h.Real || h.Lifetime <= 0 {
+		return
+	}
+	p.world.RequestSpawn(h.Type, h.Position)
+}
+
+// imagine spawns a random hallucination, more often the lower the player's sanity
+func (p *PerceptionLayer) imagine() {
+	player := p.world.player
+	if player == nil || len(p.hallucinations) >= hallucinationMaxActive {
+		return
+	}
+
+	sanity := player.Sanity / entity.MaxSanity
+	if sanity >= hallucinationSanityStart {
+		return
+	}
+
+	madness := 1 - sanity/hallucinationSanityStart
+	if common.Rand().Float64() >= madness*madness*hallucinationMaxChance {
+		return
+	}
+
+	position, ok := p.darkSpotNear(player.Position.ToCommonVector())
+	if !ok {
+		return
+	}
+
+	lifetime := hallucinationMinLifetime + common.Rand().Intn(hallucinationMaxLifetime-hallucinationMinLifetime)
+
+	switch kind := HallucinationKind(common.Rand().Intn(4)); kind {
+	case HallucinationCreature:
+		h := p.Spawn(kind, hallucinationCreatures[common.Rand().Intn(len(hallucinationCreatures))], position, lifetime)
+		h.Real = common.Rand().Float64() < hallucinationRealChance
+		if h.Real && distance(position, player.Position.ToCommonVector()) < p.vanishDistance(h) {
+			h.Real = false // Too close to step out fairly, so it stays imagined
+		}
+	case HallucinationObject:
+		p.Spawn(kind, hallucinationObjects[common.Rand().Intn(len(hallucinationObjects))], position, lifetime)
+	case HallucinationLight:
+		p.Spawn(kind, "lantern", position, lifetime)
+	case HallucinationPath:
+		p.Spawn(kind, "footprints", position, lifetime)
+	}
+}
+
+// darkSpotNear finds an open, unlit point around the player
+func (p *PerceptionLayer) darkSpotNear(center common.Vector2D) (common.Vector2D, bool) {
+	for attempt := 0; attempt < 8; attempt++ {
+		angle := common.Rand().Float64() * 2 * math.Pi
+		dist := hallucinationMinDistance + common.Rand().Float64()*(hallucinationMaxDistance-hallucinationMinDistance)
+		position := common.Vector2D{
+			X: center.X + math.Cos(angle)*dist,
+			Y: center.Y + math.Sin(angle)*dist,
+		}
+
+		if p.world.Collision().CheckCollision(position) {
+			continue
+		}
+		if p.world.light != nil && p.world.light.LightLevelAt(position) > hallucinationLitLevel {
+			continue
+		}
+		return position, true
+	}
+
+	return common.Vector2D{}, false
+}
+
+// footprints lays a wandering trail of footprints from the start point
+func (p *PerceptionLayer) footprints(start common.Vector2D, direction float64) []common.Vector2D {
+	path := make([]common.Vector2D, 0, hallucinationPathLength)
+	position := start
+	for i := 0; i < hallucinationPathLength; i++ {
+		path = append(path, position)
+		direction += (common.Rand().Float64() - 0.5) * 0.4
+		position = common.Vector2D{
+			X: position.X + math.Cos(direction)*hallucinationPathStep,
+			Y: position.Y + math.Sin(direction)*hallucinationPathStep,
+		}
+	}
+	return path
+}
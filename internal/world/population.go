@@ -0,0 +1,308 @@
+package world
+
+import (
+	"math"
+	"time"
+
+	"nightmare/internal/common"
+	"nightmare/internal/entity"
+)
+
+// Population defaults
+const (
+	DefaultGlobalBudget = 24 // Maximum number of active creatures in the world
+
+	spawnSearchRadius    = 12.0  // How far from the requested point a spawn tile may be
+	spawnSearchTries     = 16    // Attempts to find a valid spawn tile
+	spawnMinDistance     = 8.0   // Creatures never spawn closer than this to the player
+	hibernateDistance    = 60.0  // Unseen creatures farther than this are put to sleep
+	wakeDistance         = 45.0  // Hibernating creatures closer than this wake up
+	despawnDistance      = 120.0 // Hibernating creatures farther than this are removed
+	populationUpdateRate = 30    // Frames between population checks
+)
+
+// PopulationManager keeps the number of creatures in the world within budget.
+// It validates spawn points, respects safe zones and puts distant unseen creatures to sleep.
+type PopulationManager struct {
+	world        *World
+	zones        []Zone
+	GlobalBudget int
+	ZoneBudgets  map[ZoneType]int // Maximum number of active creatures per zone of each type
+	hibernating  []*Entity
+	frame        int
+
+	// Fairness rules, usually set by the director
+	MinPlayerDistance  float64   // Creatures never spawn closer than this to the player
+	RespectSafeZones   bool      // No creature spawns in a safe zone, whatever its budget
+	RequireEscapeRoute bool      // A spawn may not cut the player off from every safe zone
+	graceUntil         time.Time // No creature spawns before this time
+}
+
+// NewPopulationManager creates a new population manager
+func NewPopulationManager(world *World) *PopulationManager {
+	return &PopulationManager{
+		world:        world,
+		zones:        []Zone{},
+		GlobalBudget: DefaultGlobalBudget,
+		ZoneBudgets: map[ZoneType]int{
+			ZoneSafe:        0,
+			ZoneTransition:  3,
+			ZoneExploration: 5,
+			ZoneDanger:      7,
+			ZoneNightmare:   9,
+		},
+		hibernating: []*Entity{},
+
+		MinPlayerDistance:  spawnMinDistance,
+		RespectSafeZones:   true,
+		RequireEscapeRoute: true,
+	}
+}
+
+// SetZones sets the zones used for per-zone budgets
+func (p *PopulationManager) SetZones(zones []Zone) {
+	p.zones = zones
+}
+
+// ActiveCount returns the number of creatures currently simulated in the world
+func (p *PopulationManager) ActiveCount() int {
+	count := 0
+	for _, e := range p.world.Entities {
+		if e.Creature != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// HibernatingCount returns the number of sleeping creatures
+func (p *PopulationManager) HibernatingCount() int {
+	return len(p.hibernating)
+}
+
+// Spawn creates a creature on a valid hidden tile near the requested point.
+// Returns nil if the budget is exhausted or no suitable tile was found.
+func (p *PopulationManager) Spawn(creatureType string, near common.Vector2D) *Entity {
+	return p.spawnNear(near, func(position common.Vector2D) *Entity {
+		return p.world.SpawnCreature(creatureType, position)
+	})
+}
+
+// SpawnGenome creates a creature grown from the genome, with the same checks as Spawn
+func (p *PopulationManager) SpawnGenome(genome *entity.Genome, near common.Vector2D) *Entity {
+	return p.spawnNear(near, func(position common.Vector2D) *Entity {
+		return p.world.SpawnGenome(genome, position)
+	})
+}
+
+// spawnNear finds a valid spawn tile near the point and creates a creature there
+func (p *PopulationManager) spawnNear(near common.Vector2D, create func(common.Vector2D) *Entity) *Entity {
+	if p.ActiveCount() >= p.GlobalBudget {
+		return nil
+	}
+
+	for attempt := 0; attempt < spawnSearchTries; attempt++ {
+		position := near
+		if attempt > 0 {
+			angle := common.Rand().Float64() * 2 * math.Pi
+			dist := common.Rand().Float64() * spawnSearchRadius
+			position = common.Vector2D{
+				X: near.X + math.Cos(angle)*dist,
+				Y: near.Y + math.Sin(angle)*dist,
+			}
+		}
+
+		if !p.canSpawnAt(position) {
+			continue
+		}
+
+		return create(position)
+	}
+
+	return nil
+}
+
+// canSpawnAt checks that a creature may appear at the position
+func (p *PopulationManager) canSpawnAt(position common.Vector2D) bool {
+	tile := p.world.tileAt(position)
+	if tile == nil || p.world.Collision().isTileSolid(tile) {
+		return false
+	}
+
+	if p.world.Collision().CheckCollision(position) {
+		return false
+	}
+
+	if !p.hasZoneCapacity(position) || !p.isFair(position) {
+		return false
+	}
+
+	return !p.isWatched(position, p.MinPlayerDistance)
+}
+
+// isFair checks the fairness rules for a creature appearing at the position.
+// Unlike canSpawnAt it does not care whether the player can see the spot.
+func (p *PopulationManager) isFair(position common.Vector2D) bool {
+	if common.Now().Before(p.graceUntil) {
+		return false
+	}
+
+	if player := p.world.player; player != nil &&
+		distance(position, player.Position.ToCommonVector()) < p.MinPlayerDistance {
+		return false
+	}
+
+	if zone := p.zoneAt(position); p.RespectSafeZones && zone != nil && zone.Type == ZoneSafe {
+		return false
+	}
+
+	return !p.RequireEscapeRoute || p.hasEscapeRoute(position)
+}
+
+// hasZoneCapacity checks the budget of the zone containing the position
+func (p *PopulationManager) hasZoneCapacity(position common.Vector2D) bool {
+	zone := p.zoneAt(position)
+	if zone == nil {
+		return true
+	}
+
+	budget := p.ZoneBudgets[zone.Type]
+	if budget <= 0 {
+		return false
+	}
+
+	count := 0
+	for _, e := range p.world.Entities {
+		if e.Creature != nil && distance(e.Position, zone.Position) < zone.Radius {
+			count++
+		}
+	}
+
+	return count < budget
+}
+
+// zoneAt returns the zone containing the position, or nil
+func (p *PopulationManager) zoneAt(position common.Vector2D) *Zone {
+	if i := p.zoneIndexAt(position); i >= 0 {
+		return &p.zones[i]
+	}
+	return nil
+}
+
+// zoneIndexAt returns the index of the zone containing the position, or -1
+func (p *PopulationManager) zoneIndexAt(position common.Vector2D) int {
+	for i := range p.zones {
+		if distance(position, p.zones[i].Position) < p.zones[i].Radius {
+			return i
+		}
+	}
+	return -1
+}
+
+// nearestZone returns the index of the zone containing the position or, between zones,
+// of the zone whose edge is closest; -1 if there are no zones
+func (p *PopulationManager) nearestZone(position common.Vector2D) int {
+	nearest, nearestDist := -1, math.Inf(1)
+	for i, zone := range p.zones {
+		dist := distance(position, zone.Position) - zone.Radius
+		if dist < nearestDist {
+			nearest, nearestDist = i, dist
+		}
+	}
+	return nearest
+}
+
+// hasEscapeRoute checks that a threat at the position leaves the player a way to safety:
+// following the zone graph from the player's zone, some safe zone must be reachable
+// without passing through the zone the threat is in
+func (p *PopulationManager) hasEscapeRoute(threat common.Vector2D) bool {
+	player := p.world.player
+	if player == nil || len(p.zones) == 0 {
+		return true
+	}
+
+	start := p.nearestZone(player.Position.ToCommonVector())
+	blocked := p.zoneIndexAt(threat)
+
+	visited := make([]bool, len(p.zones))
+	visited[start] = true
+	queue := []int{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if p.zones[current].Type == ZoneSafe && current != blocked {
+			return true
+		}
+
+		for _, next := range p.zones[current].Connections {
+			if next == blocked || next < 0 || next >= len(p.zones) || visited[next] {
+				continue
+			}
+			visited[next] = true
+			queue = append(queue, next)
+		}
+	}
+
+	return false
+}
+
+// isWatched checks whether the player is too close to the position or can see it
+func (p *PopulationManager) isWatched(position common.Vector2D, minDistance float64) bool {
+	player := p.world.player
+	if player == nil {
+		return false
+	}
+
+	if distance(position, player.Position.ToCommonVector()) < minDistance {
+		return true
+	}
+
+	return player.CanSee(entity.FromCommonVector(position), p.world.Collision())
+}
+
+// Update culls dead creatures and moves creatures in and out of hibernation
+func (p *PopulationManager) Update() {
+	p.frame++
+	if p.frame%populationUpdateRate != 0 || p.world.player == nil {
+		return
+	}
+
+	playerPos := p.world.player.Position.ToCommonVector()
+
+	// Put distant unseen creatures to sleep and drop the dead ones
+	active := p.world.Entities[:0]
+	for _, e := range p.world.Entities {
+		if e.Creature != nil {
+			if e.Creature.IsDead() {
+				continue
+			}
+
+			if distance(e.Position, playerPos) > hibernateDistance && !p.isWatched(e.Position, 0) {
+				p.hibernating = append(p.hibernating, e)
+				continue
+			}
+		}
+		active = append(active, e)
+	}
+	p.world.Entities = active
+
+	// Wake up creatures the player is approaching, forget the ones left far behind
+	sleeping := p.hibernating[:0]
+	for _, e := range p.hibernating {
+		dist := distance(e.Position, playerPos)
+
+		// In an endless world they stay with their chunk instead, to be met again on the way back
+		if dist > despawnDistance && p.world.stream == nil {
+			continue
+		}
+
+		if dist < wakeDistance && !p.isWatched(e.Position, 0) && p.ActiveCount() < p.GlobalBudget {
+			p.world.Entities = append(p.world.Entities, e)
+			continue
+		}
+
+		sleeping = append(sleeping, e)
+	}
+	p.hibernating = sleeping
+}
@@ -0,0 +1,64 @@
+package world
+
+import (
+	"math"
+
+	"nightmare/internal/common"
+)
+
+const spatialCellSize = 16.0 // Side of a spatial grid cell in world units
+
+// spatialGrid buckets entities into square cells, so that the entities near a point
+// are found without scanning the whole world
+type spatialGrid struct {
+	cellSize float64
+	cells    map[[2]int][]*Entity
+}
+
+// newSpatialGrid creates an empty grid
+func newSpatialGrid(cellSize float64) *spatialGrid {
+	return &spatialGrid{
+		cellSize: cellSize,
+		cells:    make(map[[2]int][]*Entity),
+	}
+}
+
+// rebuild puts every entity into the cell it currently stands in
+func (g *spatialGrid) rebuild(entities []*Entity) {
+	for key := range g.cells {
+		delete(g.cells, key)
+	}
+	for _, e := range entities {
+		g.insert(e)
+	}
+}
+
+// insert adds an entity to the cell it stands in
+func (g *spatialGrid) insert(e *Entity) {
+	key := g.cellOf(e.Position)
+	g.cells[key] = append(g.cells[key], e)
+}
+
+// query returns the entities within the radius of the center
+func (g *spatialGrid) query(center common.Vector2D, radius float64) []*Entity {
+	result := []*Entity{}
+
+	from := g.cellOf(common.Vector2D{X: center.X - radius, Y: center.Y - radius})
+	to := g.cellOf(common.Vector2D{X: center.X + radius, Y: center.Y + radius})
+	for y := from[1]; y <= to[1]; y++ {
+		for x := from[0]; x <= to[0]; x++ {
+			for _, e := range g.cells[[2]int{x, y}] {
+				if distance(center, e.Position) <= radius {
+					result = append(result, e)
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// cellOf returns the cell containing the position
+func (g *spatialGrid) cellOf(position common.Vector2D) [2]int {
+	return [2]int{int(math.Floor(position.X / g.cellSize)), int(math.Floor(position.Y / g.cellSize))}
+}
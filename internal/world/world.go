@@ -2,9 +2,10 @@ package world
 
 import (
 	"math"
-	"math/rand"
+	"time"
 
 	"nightmare/internal/common"
+	"nightmare/internal/entity"
 
 	"github.com/ojrac/opensimplex-go"
 )
@@ -26,6 +27,12 @@ type Tile struct {
 
 // We'll use common.WorldObject instead
 
+// supplyChance is the chance that something lost lies on a grass tile
+const supplyChance = 0.002
+
+// supplyItems are the names of the items lost in the forest
+var supplyItems = []string{"Sanity Pills", "First Aid Kit"}
+
 // Entity represents an entity in the world
 type Entity struct {
 	ID        int
@@ -34,6 +41,7 @@ type Entity struct {
 	Direction float64
 	Model     *EntityModel
 	Behavior  EntityBehavior
+	Creature  *entity.Creature // Simulated creature backing this entity, if any
 }
 
 // EntityModel represents an entity model
@@ -67,6 +75,19 @@ type World struct {
 	Objects  []common.WorldObject // Using common.WorldObject
 	nextID   int
 	noise    opensimplex.Noise // Noise generator for procedural generation
+
+	player    *entity.Player      // Player that creatures react to
+	collision *CollisionSystem    // Created on demand for creature movement
+	light     entity.LightSampler // Light queries for light-sensitive creatures
+
+	spatial      *spatialGrid // Created on demand, finds entities near a point
+	spatialStale bool         // Entities moved since the grid was last rebuilt
+
+	population  *PopulationManager        // Created on demand, owns creature budgets
+	perception  *PerceptionLayer          // Created on demand, holds hallucinations
+	creatureGen *entity.CreatureGenerator // Builds creature bodies and genomes
+
+	stream *chunkStream // Chunks of an endless world; nil for a world of fixed size
 }
 
 // NewWorld creates a new world
@@ -79,7 +100,9 @@ func NewWorld(width, height int) (*World, error) {
 		Entities: []*Entity{},
 		Objects:  []common.WorldObject{},
 		nextID:   1,
-		noise:    opensimplex.New(rand.Int63()),
+		noise:    opensimplex.New(common.Rand().Int63()),
+
+		creatureGen: entity.NewCreatureGenerator(),
 	}
 
 	// Initialize tiles
@@ -103,6 +126,11 @@ func NewWorld(width, height int) (*World, error) {
 	// Place objects
 	world.placeObjects()
 
+	// Lay out zones without touching the terrain, so safe zones and escape routes exist
+	generator := NewGenerator(world, width, height)
+	generator.layoutZones()
+	world.Population().SetZones(generator.zones)
+
 	return world, nil
 }
 
@@ -160,94 +188,537 @@ func (w *World) determineTileType(elevation, moisture float64) TileType {
 
 // placeObjects places objects in the world
 func (w *World) placeObjects() {
-	// Place trees in forest areas
+	// Trees grow in forest areas, rocks lie among the rocks
 	for y := 0; y < w.Height; y++ {
 		for x := 0; x < w.Width; x++ {
 			tile := &w.Tiles[y][x]
 
-			if tile.Type == common.TileForest {
-				if rand.Float64() < 0.2 {
-					tree := common.WorldObject{
-						ID:          w.nextID,
-						Type:        "tree",
-						Position:    common.Vector2D{X: float64(x), Y: float64(y)},
-						Solid:       true,
-						Interactive: false,
-					}
-					tile.Objects = append(tile.Objects, tree)
-					w.Objects = append(w.Objects, tree)
-					w.nextID++
-				}
-			} else if tile.Type == common.TileDenseForest {
-				if rand.Float64() < 0.5 {
-					tree := common.WorldObject{
-						ID:          w.nextID,
-						Type:        "dense_tree",
-						Position:    common.Vector2D{X: float64(x), Y: float64(y)},
-						Solid:       true,
-						Interactive: false,
-					}
-					tile.Objects = append(tile.Objects, tree)
-					w.Objects = append(w.Objects, tree)
-					w.nextID++
-				}
-			} else if tile.Type == common.TileRocks {
-				if rand.Float64() < 0.1 {
-					rock := common.WorldObject{
-						ID:          w.nextID,
-						Type:        "rock",
-						Position:    common.Vector2D{X: float64(x), Y: float64(y)},
-						Solid:       true,
-						Interactive: false,
-					}
-					tile.Objects = append(tile.Objects, rock)
-					w.Objects = append(w.Objects, rock)
-					w.nextID++
-				}
+			object, ok := naturalObject(tile.Type, common.Rand().Float64)
+			if !ok {
+				continue
 			}
+			object.ID = w.nextID
+			object.Position = common.Vector2D{X: float64(x), Y: float64(y)}
+			tile.Objects = append(tile.Objects, object)
+			w.Objects = append(w.Objects, object)
+			w.nextID++
 		}
 	}
 }
 
+// naturalObject rolls the tree, rock or lost supplies found on a tile of the given type
+func naturalObject(tileType TileType, roll func() float64) (common.WorldObject, bool) {
+	switch tileType {
+	case common.TileGrass:
+		if roll() < supplyChance {
+			name := supplyItems[int(roll()*float64(len(supplyItems)))%len(supplyItems)]
+			return common.WorldObject{Type: "item", Name: name, Interactive: true}, true
+		}
+	case common.TileForest:
+		if roll() < 0.2 {
+			return common.WorldObject{Type: "tree", Solid: true}, true
+		}
+	case common.TileDenseForest:
+		if roll() < 0.5 {
+			return common.WorldObject{Type: "dense_tree", Solid: true}, true
+		}
+	case common.TileRocks:
+		if roll() < 0.1 {
+			return common.WorldObject{Type: "rock", Solid: true}, true
+		}
+	}
+	return common.WorldObject{}, false
+}
+
 // Update updates the world state
 func (w *World) Update() {
+	// An endless world follows the player, loading the chunks ahead and storing the ones left behind
+	w.streamChunks()
+
+	// Creatures take courage from packmates nearby
+	w.updatePacks()
+
 	// Update all entities
 	for _, entity := range w.Entities {
 		if entity.Behavior != nil {
 			entity.Behavior.Update(w, entity)
 		}
+		if entity.Creature != nil {
+			w.updateCreature(entity)
+		}
+	}
+
+	// Hallucinations come and go with the player's sanity
+	w.Perception().Update()
+
+	// Keep the creature population within budget
+	if w.population != nil {
+		w.population.Update()
+	}
+
+	// Entities have moved, the spatial grid is rebuilt on the next query
+	w.spatialStale = true
+}
+
+// updatePacks tells each creature how many creatures of its type are nearby
+func (w *World) updatePacks() {
+	const packRadius = 10.0
+
+	for _, e := range w.Entities {
+		if e.Creature == nil || e.Creature.IsDead() {
+			continue
+		}
+
+		count := 0
+		center := common.Vector2D{}
+		for _, other := range w.Entities {
+			if other == e || other.Creature == nil || other.Creature.IsDead() || other.Type != e.Type {
+				continue
+			}
+			if distance(e.Position, other.Position) > packRadius {
+				continue
+			}
+			count++
+			center.X += other.Position.X
+			center.Y += other.Position.Y
+		}
+
+		if count > 0 {
+			center.X /= float64(count)
+			center.Y /= float64(count)
+		}
+		e.Creature.SetPack(count, entity.FromCommonVector(center))
+	}
+}
+
+// updateCreature steps the simulated creature and mirrors its state onto the entity
+func (w *World) updateCreature(e *Entity) {
+	creature := e.Creature
+	if creature.IsDead() {
+		return
+	}
+
+	// Creatures notice the player within their detection range
+	if w.player != nil && creature.PlayerTarget == nil &&
+		distance(e.Position, w.player.Position.ToCommonVector()) < creature.DetectionRange {
+		creature.SetTarget(w.player)
+	}
+
+	creature.Update(w.Bounds())
+
+	e.Position = creature.Position.ToCommonVector()
+	e.Direction = creature.Direction
+}
+
+// SetPlayer sets the player that creatures react to
+func (w *World) SetPlayer(player *entity.Player) {
+	w.player = player
+}
+
+// SetLightSampler sets the light queries used by light-sensitive creatures
+func (w *World) SetLightSampler(light entity.LightSampler) {
+	w.light = light
+
+	for _, e := range w.Entities {
+		if e.Creature != nil {
+			e.Creature.Light = light
+		}
+	}
+}
+
+// Collision returns the world collision system, creating it on first use
+func (w *World) Collision() *CollisionSystem {
+	if w.collision == nil {
+		w.collision = NewCollisionSystem(w, 1.0)
+		w.collision.UpdateCollisionMap()
+	}
+	return w.collision
+}
+
+// Perception returns the player's perception layer, creating it on first use
+func (w *World) Perception() *PerceptionLayer {
+	if w.perception == nil {
+		w.perception = NewPerceptionLayer(w)
+	}
+	return w.perception
+}
+
+// Population returns the world population manager, creating it on first use
+func (w *World) Population() *PopulationManager {
+	if w.population == nil {
+		w.population = NewPopulationManager(w)
+	}
+	return w.population
+}
+
+// ZoneNameAt returns the type of the zone containing the position, or an empty string
+func (w *World) ZoneNameAt(position common.Vector2D) string {
+	zone := w.Population().zoneAt(position)
+	if zone == nil {
+		return ""
+	}
+	return zone.Type.String()
+}
+
+// LightAt returns the light level (from 0 to 1) at the position.
+// ok is false if no light sampler is set.
+func (w *World) LightAt(position common.Vector2D) (level float64, ok bool) {
+	if w.light == nil {
+		return 0, false
+	}
+	return w.light.LightLevelAt(position), true
+}
+
+// OpenSpaceAt returns the share of tiles within the radius free of solid terrain
+// and solid objects: 0 when boxed in, 1 in the open
+func (w *World) OpenSpaceAt(position common.Vector2D, radius float64) float64 {
+	collision := w.Collision()
+
+	total, open := 0, 0
+	for y := int(math.Floor(position.Y - radius)); y <= int(math.Ceil(position.Y+radius)); y++ {
+		for x := int(math.Floor(position.X - radius)); x <= int(math.Ceil(position.X+radius)); x++ {
+			dx, dy := float64(x)-position.X, float64(y)-position.Y
+			if dx*dx+dy*dy > radius*radius {
+				continue
+			}
+			total++
+
+			// Beyond the edge of the world nothing is open
+			tile := w.GetTileAt(x, y)
+			if tile == nil || collision.isTileSolid(tile) || hasSolidObject(tile) {
+				continue
+			}
+			open++
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return float64(open) / float64(total)
+}
+
+// ExitsAround counts the paths leaving the square of the given radius around the position:
+// every run of path tiles crossing its border is one exit
+func (w *World) ExitsAround(position common.Vector2D, radius int) int {
+	cx, cy := int(math.Floor(position.X)), int(math.Floor(position.Y))
+
+	// Walk the border of the square clockwise
+	border := make([]bool, 0, 8*radius)
+	for i := -radius; i < radius; i++ {
+		border = append(border, w.isPathAt(cx+i, cy-radius))
+	}
+	for i := -radius; i < radius; i++ {
+		border = append(border, w.isPathAt(cx+radius, cy+i))
+	}
+	for i := radius; i > -radius; i-- {
+		border = append(border, w.isPathAt(cx+i, cy+radius))
+	}
+	for i := radius; i > -radius; i-- {
+		border = append(border, w.isPathAt(cx-radius, cy+i))
+	}
+
+	exits := 0
+	for i, path := range border {
+		previous := border[(i+len(border)-1)%len(border)]
+		if path && !previous {
+			exits++
+		}
+	}
+
+	// On a border made entirely of path every side is a way out
+	if exits == 0 && len(border) > 0 && border[0] {
+		return 4
+	}
+	return exits
+}
+
+// CreaturesNear returns the living creatures within the radius of the position
+func (w *World) CreaturesNear(position common.Vector2D, radius float64) []*entity.Creature {
+	if w.spatial == nil {
+		w.spatial = newSpatialGrid(spatialCellSize)
+		w.spatialStale = true
+	}
+	if w.spatialStale {
+		w.spatial.rebuild(w.Entities)
+		w.spatialStale = false
+	}
+
+	creatures := []*entity.Creature{}
+	for _, e := range w.spatial.query(position, radius) {
+		if e.Creature != nil && !e.Creature.IsDead() {
+			creatures = append(creatures, e.Creature)
+		}
 	}
+	return creatures
+}
+
+// isPathAt checks whether the tile at the coordinates is a path
+func (w *World) isPathAt(x, y int) bool {
+	tile := w.GetTileAt(x, y)
+	return tile != nil && tile.Type == common.TilePath
+}
+
+// hasSolidObject checks whether a solid object stands on the tile
+func hasSolidObject(tile *Tile) bool {
+	for _, object := range tile.Objects {
+		if object.Solid {
+			return true
+		}
+	}
+	return false
+}
+
+// HasEscapeRoute checks that a creature at the position would not cut the player off from every safe zone
+func (w *World) HasEscapeRoute(threat common.Vector2D) bool {
+	return w.Population().hasEscapeRoute(threat)
+}
+
+// SetSpawnRules sets the fairness rules every creature spawn must follow
+func (w *World) SetSpawnRules(minDistance float64, safeZones, escapeRoute bool, graceUntil time.Time) {
+	population := w.Population()
+	population.MinPlayerDistance = minDistance
+	population.RespectSafeZones = safeZones
+	population.RequireEscapeRoute = escapeRoute
+	population.graceUntil = graceUntil
+}
+
+// IsBlocked checks whether a position is solid or outside the world
+func (w *World) IsBlocked(position common.Vector2D) bool {
+	return w.Collision().CheckCollision(position)
+}
+
+// HasLineOfSight checks whether nothing solid stands between two points
+func (w *World) HasLineOfSight(from, to common.Vector2D) bool {
+	return w.Collision().CheckLineOfSight(from, to)
+}
+
+// RequestSpawn asks the population manager to spawn a creature near the position.
+// Returns false if the spawn was rejected.
+func (w *World) RequestSpawn(creatureType string, position common.Vector2D) bool {
+	return w.Population().Spawn(creatureType, position) != nil
 }
 
-// GetTileAt returns the tile at the specified position
+// GetTileAt returns the tile at the specified position, or nil outside the world
+// and, in an endless world, outside the loaded chunks
 func (w *World) GetTileAt(x, y int) *Tile {
+	if w.stream != nil {
+		return w.stream.tileAt(x, y)
+	}
 	if x < 0 || y < 0 || x >= w.Width || y >= w.Height {
 		return nil
 	}
 	return &w.Tiles[y][x]
 }
 
-// SpawnCreature creates a creature of the specified type at the specified position
+// tileAt returns the tile containing the position
+func (w *World) tileAt(position common.Vector2D) *Tile {
+	return w.GetTileAt(int(math.Floor(position.X)), int(math.Floor(position.Y)))
+}
+
+// Bounds returns the area where tiles exist: the whole of a fixed world,
+// the loaded chunks of an endless one
+func (w *World) Bounds() common.Bounds {
+	if w.stream != nil {
+		return w.stream.bounds
+	}
+	return common.Bounds{Max: common.Vector2D{X: float64(w.Width), Y: float64(w.Height)}}
+}
+
+// SpawnPoint returns where the player starts
+func (w *World) SpawnPoint() common.Vector2D {
+	if w.stream != nil {
+		return ChunkCoord{}.Center()
+	}
+	return common.Vector2D{X: float64(w.Width) / 2, Y: float64(w.Height) / 2}
+}
+
+// MapArea returns the area worth mapping, such as the player's visits: the whole of a fixed world,
+// the surroundings of the spawn point in an endless one
+func (w *World) MapArea() common.Bounds {
+	if w.stream != nil {
+		return mapArea()
+	}
+	return w.Bounds()
+}
+
+// DropItem leaves an item lying on the ground at the position
+func (w *World) DropItem(name string, position common.Vector2D) {
+	tile := w.tileAt(position)
+	if tile == nil {
+		return
+	}
+
+	item := common.WorldObject{
+		ID:          w.nextID,
+		Type:        "item",
+		Name:        name,
+		Position:    position,
+		Interactive: true,
+	}
+	tile.Objects = append(tile.Objects, item)
+	w.Objects = append(w.Objects, item)
+	w.nextID++
+
+	w.markModified(tile)
+}
+
+// PickUpItem offers the nearest item lying within reach of the position to take.
+// The item leaves the ground only if take accepts it. Returns false if nothing was taken.
+func (w *World) PickUpItem(position common.Vector2D, reach float64, take func(name string) bool) bool {
+	nearest := -1
+	for i, object := range w.Objects {
+		if object.Type != "item" || distance(object.Position, position) > reach {
+			continue
+		}
+		if nearest < 0 || distance(object.Position, position) < distance(w.Objects[nearest].Position, position) {
+			nearest = i
+		}
+	}
+	if nearest < 0 {
+		return false
+	}
+
+	item := w.Objects[nearest]
+	if !take(item.Name) {
+		return false
+	}
+	w.Objects = append(w.Objects[:nearest], w.Objects[nearest+1:]...)
+
+	if tile := w.tileAt(item.Position); tile != nil {
+		for i, object := range tile.Objects {
+			if object.ID == item.ID {
+				tile.Objects = append(tile.Objects[:i], tile.Objects[i+1:]...)
+				break
+			}
+		}
+		w.markModified(tile)
+	}
+	return true
+}
+
+// SpawnCreature creates a creature of the specified type at the specified position.
+// It does not check budgets or spawn points; gameplay code should use RequestSpawn.
 func (w *World) SpawnCreature(creatureType string, position common.Vector2D) *Entity {
-	// Create creature model
-	model := generateCreatureModel(creatureType)
+	creature := w.creatureGen.GenerateCreature(creatureType, entity.FromCommonVector(position))
+	return w.addCreature(creature)
+}
+
+// SpawnGenome creates a creature grown from the genome at the specified position.
+// Like SpawnCreature, it does not check budgets or spawn points.
+func (w *World) SpawnGenome(genome *entity.Genome, position common.Vector2D) *Entity {
+	creature := w.creatureGen.GenerateFromGenome(genome, entity.FromCommonVector(position))
+	return w.addCreature(creature)
+}
+
+// RequestGenomeSpawn asks the population manager to spawn a creature from the genome.
+// Returns false if the spawn was rejected.
+func (w *World) RequestGenomeSpawn(genome *entity.Genome, position common.Vector2D) bool {
+	return w.Population().SpawnGenome(genome, position) != nil
+}
+
+// Creatures returns the simulated creatures currently active in the world
+func (w *World) Creatures() []*entity.Creature {
+	creatures := []*entity.Creature{}
+	for _, e := range w.Entities {
+		if e.Creature != nil {
+			creatures = append(creatures, e.Creature)
+		}
+	}
+	return creatures
+}
+
+// CreaturesInArc returns living creatures within reach of the origin and inside
+// the arc (full angle in radians) centred on the direction
+func (w *World) CreaturesInArc(origin common.Vector2D, direction, arc, reach float64) []*Entity {
+	result := []*Entity{}
+
+	for _, e := range w.Entities {
+		if e.Creature == nil || e.Creature.IsDead() {
+			continue
+		}
+
+		dist := distance(origin, e.Position)
+		if dist > reach {
+			continue
+		}
+
+		// A creature right on top of the origin is always hit
+		if dist > 0 {
+			angle := math.Atan2(e.Position.Y-origin.Y, e.Position.X-origin.X) - direction
+			angle = math.Atan2(math.Sin(angle), math.Cos(angle))
+			if math.Abs(angle) > arc/2 {
+				continue
+			}
+		}
+
+		// Walls block the swing
+		if !w.Collision().CheckLineOfSight(origin, e.Position) {
+			continue
+		}
+
+		result = append(result, e)
+	}
+
+	return result
+}
+
+// addCreature wraps a simulated creature into a world entity
+func (w *World) addCreature(creature *entity.Creature) *Entity {
+	// Creature IDs follow world entity IDs
+	creature.ID = w.nextID
+	creature.Terrain = w.Collision()
+	creature.Light = w.light
 
 	// Create entity
 	entity := &Entity{
 		ID:        w.nextID,
-		Type:      creatureType,
-		Position:  position,
-		Direction: rand.Float64() * 2 * math.Pi,
-		Model:     model,
+		Type:      creature.Type,
+		Position:  creature.Position.ToCommonVector(),
+		Direction: creature.Direction,
+		Model:     generateCreatureModel(creature.Type),
 		Behavior:  NewBasicCreatureBehavior(),
+		Creature:  creature,
 	}
 
 	w.Entities = append(w.Entities, entity)
 	w.nextID++
+	w.spatialStale = true
 
 	return entity
 }
 
+// SpawnMimic creates a doppelganger that replays the given player route.
+// It returns false if the mimic may not appear.
+func (w *World) SpawnMimic(route []common.Vector2D) bool {
+	if len(route) == 0 {
+		return false
+	}
+
+	// The route was walked by the player, so only the budget and fairness need checking
+	population := w.Population()
+	if population.ActiveCount() >= population.GlobalBudget || !population.hasZoneCapacity(route[0]) ||
+		!population.isFair(route[0]) {
+		return false
+	}
+
+	path := make([]entity.Vector2D, len(route))
+	for i, point := range route {
+		path[i] = entity.FromCommonVector(point)
+	}
+
+	mimic := w.SpawnCreature("doppelganger", route[0])
+	mimic.Creature.SetReplayPath(path)
+	return true
+}
+
+// SpawnHallucination shows a creature that is probably not there for the given number of frames
+func (w *World) SpawnHallucination(creatureType string, position common.Vector2D, frames int) *Hallucination {
+	h := w.Perception().Spawn(HallucinationCreature, creatureType, position, frames)
+	h.Real = common.Rand().Float64() < hallucinationRealChance
+	return h
+}
+
 // ModifyEnvironment changes the environment around the specified position
 func (w *World) ModifyEnvironment(position common.Vector2D, intensity float64) {
 	// Influence radius
@@ -255,10 +726,11 @@ func (w *World) ModifyEnvironment(position common.Vector2D, intensity float64) {
 	radiusSq := radius * radius
 
 	// Change tiles around the position
-	for y := int(position.Y - radius); y <= int(position.Y+radius); y++ {
-		for x := int(position.X - radius); x <= int(position.X+radius); x++ {
+	for y := int(math.Floor(position.Y - radius)); y <= int(math.Floor(position.Y+radius)); y++ {
+		for x := int(math.Floor(position.X - radius)); x <= int(math.Floor(position.X+radius)); x++ {
 			// Check that coordinates are within the world
-			if x < 0 || y < 0 || x >= w.Width || y >= w.Height {
+			tile := w.GetTileAt(x, y)
+			if tile == nil {
 				continue
 			}
 
@@ -273,7 +745,6 @@ func (w *World) ModifyEnvironment(position common.Vector2D, intensity float64) {
 				strength := (1.0 - distSq/radiusSq) * intensity
 
 				// Increase "nightmareness" level
-				tile := &w.Tiles[y][x]
 				tile.Corruption += strength * 0.3
 
 				// Limit value
@@ -285,9 +756,17 @@ func (w *World) ModifyEnvironment(position common.Vector2D, intensity float64) {
 				if tile.Corruption > 0.7 {
 					tile.Type = common.TileCorrupted
 				}
+
+				// An endless world must remember the change when the chunk is unloaded
+				w.markModified(tile)
 			}
 		}
 	}
+
+	// Corrupted tiles may have become impassable
+	if w.collision != nil {
+		w.collision.UpdateCollisionMap()
+	}
 }
 
 // generateCreatureModel generates a model for a creature of the specified type